@@ -1,22 +1,33 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"gofigure/config"
 	"gofigure/internal/game"
+	"gofigure/internal/llm"
 	"gofigure/internal/logger"
+	"gofigure/internal/tts"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	cfgFile  string
-	showResp bool
-	useMic   bool
-	debug    bool
-	cfg      *config.Config
-	log      = logger.New()
+	cfgFile     string
+	showResp    bool
+	useMic      bool
+	audioInput  []string
+	strict      bool
+	printSchema bool
+	genOpts     game.GeneratorOptions
+	genOutput   string
+	debug       bool
+	cfg         *config.Config
+	log         = logger.New()
 )
 
 var rootCmd = &cobra.Command{
@@ -31,6 +42,7 @@ var playCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		mysteryFile := args[0]
+		useRecordedAudio()
 
 		e, err := game.NewEngine(cfg)
 		if err != nil {
@@ -41,21 +53,196 @@ var playCmd = &cobra.Command{
 	},
 }
 
+var resumeCmd = &cobra.Command{
+	Use:   "resume [slot]",
+	Short: "Resume a saved investigation",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		useRecordedAudio()
+
+		e, err := game.NewEngine(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create engine: %w", err)
+		}
+
+		return e.WithSave(args[0]).WithResponses(showResp).WithMicInput(useMic).Start()
+	},
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [mystery.json...]",
+	Short: "Check mystery files for mistakes",
+	Long:  "Validate mystery files against the published JSON Schema and check that killers, weapons and tts engines are consistent. Exits non-zero if any file has errors.",
+
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if printSchema {
+			fmt.Println(string(game.MysterySchema))
+			return nil
+		}
+
+		if len(args) == 0 {
+			return fmt.Errorf("at least one mystery file is required")
+		}
+
+		failed := 0
+		for _, file := range args {
+			problems := game.ValidateMysteryFile(file)
+
+			errCount := 0
+			for _, p := range problems {
+				fmt.Println(p)
+				if p.Severity == game.SeverityError || strict {
+					errCount++
+				}
+			}
+
+			if errCount > 0 {
+				failed++
+				continue
+			}
+			fmt.Printf("✅ %s is valid\n", file)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d mystery file(s) failed validation", failed, len(args))
+		}
+		return nil
+	},
+}
+
+var generateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a new mystery with the configured LLM",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("seed") {
+			genOpts.Seed = time.Now().UnixNano()
+		}
+
+		llmClient, err := llm.NewLLMClient(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to create LLM client: %w", err)
+		}
+
+		g, err := game.NewGenerator(llmClient, genOpts)
+		if err != nil {
+			return err
+		}
+
+		log.Info(fmt.Sprintf("🎲 Generating a %s mystery with %d suspects [seed:%d]", genOpts.Difficulty, genOpts.Suspects, genOpts.Seed))
+
+		m, err := g.Generate(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to generate mystery: %w", err)
+		}
+
+		data, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode mystery: %w", err)
+		}
+
+		if genOutput == "" {
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if err := os.WriteFile(genOutput, data, 0o644); err != nil {
+			return fmt.Errorf("failed to write mystery: %w", err)
+		}
+		log.Info(fmt.Sprintf("🔍 '%s' written to %s", m.Title, genOutput))
+		return nil
+	},
+}
+
+var ttsCmd = &cobra.Command{
+	Use:   "tts",
+	Short: "Manage text-to-speech",
+}
+
+var prewarmCmd = &cobra.Command{
+	Use:   "prewarm <mystery.json>...",
+	Short: "Render narration into the tts cache ahead of play",
+	Long:  "Synthesize the welcome line and introduction of each mystery into the tts cache, so they play immediately at the start of a game.",
+	Args:  cobra.MinimumNArgs(1),
+
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		t, err := tts.NewTts(ctx, &cfg.Tts)
+		if err != nil {
+			return err
+		}
+
+		cached, ok := t.(*tts.CachedTts)
+		if !ok {
+			return fmt.Errorf("the tts cache is disabled (tts.cache.enabled) or the %s engine can't be cached", t.Name())
+		}
+
+		failed := 0
+		for _, file := range args {
+			if err := game.PrewarmNarration(ctx, cached, t.Name(), file); err != nil {
+				fmt.Printf("❌ %v\n", err)
+				failed++
+				continue
+			}
+			fmt.Printf("✅ %s narration cached\n", file)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d mystery file(s) could not be prewarmed", failed, len(args))
+		}
+		return nil
+	},
+}
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Show current configuration",
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Printf("Current Configuration:\n")
+		if len(cfg.LLM.Providers) == 0 {
+			fmt.Printf("  LLM Provider: %s\n", cfg.LLM.Provider)
+		}
+		for i, p := range cfg.LLM.Providers {
+			fmt.Printf("  LLM Provider %d: %s (retries: %d)\n", i+1, p.Name, p.Retries)
+		}
 		fmt.Printf("  Ollama Host: %s\n", cfg.Ollama.Host)
 		fmt.Printf("  Ollama Model: %s\n", cfg.Ollama.Model)
 		fmt.Printf("  Timeout: %d seconds\n", cfg.Ollama.Timeout)
 		fmt.Printf("  TTS Enabled: %t\n", cfg.Tts.Enabled)
+		fmt.Printf("  TTS Engine: %s\n", activeTtsEngine())
 		fmt.Printf("  SST Enabled: %t\n", cfg.Sst.Enabled)
 		fmt.Printf("  SST Provider: %s\n", cfg.Sst.Provider)
 		fmt.Printf("  SST Language: %s\n", cfg.Sst.LanguageCode)
+		fmt.Printf("  Save Directory: %s\n", cfg.Game.SaveDir)
 	},
 }
 
+// activeTtsEngine reports which tts engine the game would use after falling
+// back, working it out from the config alone
+func activeTtsEngine() string {
+	configured := strings.Join(append([]string{cfg.Tts.Type}, cfg.Tts.Fallback...), " -> ")
+
+	name, err := tts.ActiveEngine(&cfg.Tts)
+	if err != nil {
+		return fmt.Sprintf("none (tried %s)", configured)
+	}
+	return fmt.Sprintf("%s (configured: %s)", name, configured)
+}
+
+// useRecordedAudio replaces the microphone with the --audio-input recordings,
+// which implies voice mode
+func useRecordedAudio() {
+	if len(audioInput) == 0 {
+		return
+	}
+	cfg.Sst.AudioInput = audioInput
+	cfg.Sst.Enabled = true
+	useMic = true
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
@@ -64,6 +251,19 @@ func init() {
 
 	// Add mic flag to play command specifically
 	playCmd.Flags().BoolVar(&useMic, "mic", false, "enable microphone input during interviews (push-to-talk)")
+	resumeCmd.Flags().BoolVar(&useMic, "mic", false, "enable microphone input during interviews (push-to-talk)")
+	playCmd.Flags().StringSliceVar(&audioInput, "audio-input", nil, "answer voice prompts from WAV or raw PCM recordings instead of the microphone, one per prompt ('-' for stdin)")
+	resumeCmd.Flags().StringSliceVar(&audioInput, "audio-input", nil, "answer voice prompts from WAV or raw PCM recordings instead of the microphone, one per prompt ('-' for stdin)")
+
+	validateCmd.Flags().BoolVar(&strict, "strict", false, "treat warnings as errors")
+	validateCmd.Flags().BoolVar(&printSchema, "schema", false, "print the mystery JSON Schema and exit")
+
+	generateCmd.Flags().StringVar(&genOpts.Setting, "setting", "", "where and when the mystery takes place")
+	generateCmd.Flags().IntVar(&genOpts.Suspects, "suspects", 6, "number of suspects")
+	generateCmd.Flags().StringVar(&genOpts.Difficulty, "difficulty", "medium", "easy, medium or hard")
+	generateCmd.Flags().Int64Var(&genOpts.Seed, "seed", 0, "seed for reproducible generation (random if unset)")
+	generateCmd.Flags().StringVarP(&genOutput, "output", "o", "", "write the mystery to a file instead of stdout")
+	generateCmd.MarkFlagRequired("setting")
 }
 
 func initConfig() {
@@ -77,8 +277,14 @@ func initConfig() {
 
 func main() {
 	rootCmd.AddCommand(playCmd)
+	rootCmd.AddCommand(resumeCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(generateCmd)
 	rootCmd.AddCommand(configCmd)
 
+	ttsCmd.AddCommand(prewarmCmd)
+	rootCmd.AddCommand(ttsCmd)
+
 	logger.GlobalLogLevel = logger.LogLevelInfo
 	if debug {
 		logger.GlobalLogLevel = logger.LogLevelDebug
@@ -10,11 +10,25 @@ type Config struct {
 	OpenAI OpenAIConfig `mapstructure:"openai"`
 	Tts    TtsConfig    `mapstructure:"tts"`
 	Sst    SstConfig    `mapstructure:"sst"`
+	Game   GameConfig   `mapstructure:"game"`
 }
 
 // LLM provider selection
 type LLMConfig struct {
-	Provider string `mapstructure:"provider"` // "ollama" or "openai"
+	Provider     string           `mapstructure:"provider"`      // "ollama" or "openai"
+	Providers    []ProviderConfig `mapstructure:"providers"`     // ordered fallback chain, overrides provider when set
+	ReplyRetries int              `mapstructure:"reply_retries"` // corrective re-prompts when a reply can't be parsed
+	JSONMode     bool             `mapstructure:"json_mode"`     // use the provider's native JSON mode for replies
+}
+
+// ProviderConfig is one link in the llm fallback chain. The provider itself is
+// configured by its own section (ollama or openai).
+type ProviderConfig struct {
+	Name             string `mapstructure:"name"`              // "ollama" or "openai"
+	Retries          int    `mapstructure:"retries"`           // extra attempts on timeouts, rate limits and server errors
+	Backoff          int    `mapstructure:"backoff_ms"`        // wait before the first retry, doubled each time
+	FailureThreshold int    `mapstructure:"failure_threshold"` // consecutive failures before the provider is skipped
+	Cooldown         int    `mapstructure:"cooldown"`          // seconds a failing provider is skipped for
 }
 
 // New OpenAI config
@@ -24,11 +38,28 @@ type OpenAIConfig struct {
 	BaseURL   string `mapstructure:"base_url"`   // Optional, defaults to OpenAI API
 	MaxTokens int    `mapstructure:"max_tokens"` // Optional, defaults to model's max
 	Timeout   int    `mapstructure:"timeout"`
+
+	StructuredOutput bool `mapstructure:"structured_output"` // constrain replies to a JSON schema, if the model supports it
 }
 
 type TtsConfig struct {
-	Type    string `mapstructure:"type"`
+	Type     string      `mapstructure:"type"`
+	Fallback []string    `mapstructure:"fallback"` // engines tried in order if type can't be created
+	Enabled  bool        `mapstructure:"enabled"`
+	Piper    PiperConfig `mapstructure:"piper"`
+	Cache    CacheConfig `mapstructure:"cache"`
+}
+
+type CacheConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
+	Dir     string `mapstructure:"dir"`
+	MaxMB   int    `mapstructure:"max_mb"` // least recently used speech is evicted beyond this
+}
+
+type PiperConfig struct {
+	Binary    string `mapstructure:"binary"`     // piper executable, looked up on PATH
+	VoicesDir string `mapstructure:"voices_dir"` // directory holding <voice>.onnx and <voice>.onnx.json
+	Voice     string `mapstructure:"voice"`      // used when a character has no piper voice
 }
 
 type SstConfig struct {
@@ -36,12 +67,36 @@ type SstConfig struct {
 	Provider     string `mapstructure:"provider"`
 	LanguageCode string `mapstructure:"language_code"`
 	SampleRate   int    `mapstructure:"sample_rate"`
+
+	WhisperBinary string `mapstructure:"whisper_binary"` // whisper.cpp executable, looked up on PATH
+	WhisperModel  string `mapstructure:"whisper_model"`  // path to a ggml model, e.g. ggml-base.en.bin
+
+	MinConfidence float64 `mapstructure:"min_confidence"` // less certain transcripts are confirmed first, 0 to never ask
+
+	Mode string    `mapstructure:"mode"` // "push_to_talk" or "vad" for hands-free questioning
+	VAD  VADConfig `mapstructure:"vad"`
+
+	AudioInput []string `mapstructure:"audio_input"` // recordings replayed instead of the microphone, one per question
+}
+
+// VADConfig tunes voice activity detection in vad mode
+type VADConfig struct {
+	Threshold   float64 `mapstructure:"threshold"`     // speech level between 0 and 1
+	SilenceMs   int     `mapstructure:"silence_ms"`    // trailing silence that ends a question
+	MinSpeechMs int     `mapstructure:"min_speech_ms"` // louder sounds shorter than this are ignored
+}
+
+type GameConfig struct {
+	SaveDir      string `mapstructure:"save_dir"`      // directory holding save slots
+	ExtractClues bool   `mapstructure:"extract_clues"` // ask the llm to file clues from each answer
 }
 
 type OllamaConfig struct {
 	Host    string `mapstructure:"host"`
 	Model   string `mapstructure:"model"`
 	Timeout int    `mapstructure:"timeout"` // seconds
+
+	StructuredOutput bool `mapstructure:"structured_output"` // constrain replies to a JSON schema, if the model supports it
 }
 
 func Load() (*Config, error) {
@@ -65,14 +120,31 @@ func Load() (*Config, error) {
 	viper.SetDefault("ollama.timeout", 30)
 
 	viper.SetDefault("llm.provider", "openai")
+	viper.SetDefault("llm.reply_retries", 2)
+	viper.SetDefault("llm.json_mode", true)
 
 	viper.SetDefault("tts.enabled", true)
 	viper.SetDefault("tts.type", "google")
+	viper.SetDefault("tts.piper.binary", "piper")
+	viper.SetDefault("tts.piper.voices_dir", "voices")
+	viper.SetDefault("tts.piper.voice", "en_GB-alan-medium")
+	viper.SetDefault("tts.cache.enabled", true)
+	viper.SetDefault("tts.cache.dir", "cache/tts")
+	viper.SetDefault("tts.cache.max_mb", 200)
 
 	viper.SetDefault("sst.enabled", true)
 	viper.SetDefault("sst.provider", "google")
 	viper.SetDefault("sst.language_code", "en-US")
 	viper.SetDefault("sst.sample_rate", 16000)
+	viper.SetDefault("sst.whisper_binary", "whisper-cli")
+	viper.SetDefault("sst.min_confidence", 0.6)
+	viper.SetDefault("sst.mode", "push_to_talk")
+	viper.SetDefault("sst.vad.threshold", 0.02)
+	viper.SetDefault("sst.vad.silence_ms", 1200)
+	viper.SetDefault("sst.vad.min_speech_ms", 200)
+
+	viper.SetDefault("game.save_dir", "saves")
+	viper.SetDefault("game.extract_clues", true)
 
 	// Allow environment variables
 	viper.SetEnvPrefix("GOFIGURE")
@@ -1,6 +1,8 @@
 package audio
 
 import (
+	"bytes"
+	"context"
 	"log"
 	"os"
 	"time"
@@ -9,15 +11,20 @@ import (
 	"github.com/faiface/beep/effects"
 	"github.com/faiface/beep/mp3"
 	"github.com/faiface/beep/speaker"
+	"github.com/faiface/beep/wav"
 )
 
 var musicCtrl *beep.Ctrl
 
 var mixer = &beep.Mixer{}
 
+// speakerRate is the rate the speaker was initialised with, zero until then
+var speakerRate beep.SampleRate
+
 func InitAudio(sampleRate beep.SampleRate) {
 	speaker.Init(sampleRate, sampleRate.N(time.Second/10))
 	speaker.Play(mixer) // play everything from mixer
+	speakerRate = sampleRate
 }
 
 // Background music
@@ -30,6 +37,43 @@ func PlayTTS(streamer beep.Streamer) {
 	mixer.Add(streamer)
 }
 
+// PlayWAV plays a WAV recording on the tts channel, resampling it to the
+// speaker's rate, and returns once it has finished or ctx is cancelled
+func PlayWAV(ctx context.Context, data []byte) error {
+	stream, format, err := wav.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	// background music normally initialises the speaker, but it may be missing
+	if speakerRate == 0 {
+		InitAudio(format.SampleRate)
+	}
+
+	var streamer beep.Streamer = stream
+	if format.SampleRate != speakerRate {
+		streamer = beep.Resample(4, format.SampleRate, speakerRate, stream)
+	}
+
+	done := make(chan struct{})
+	ctrl := &beep.Ctrl{Streamer: beep.Seq(streamer, beep.Callback(func() {
+		close(done)
+	}))}
+
+	PlayTTS(ctrl)
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		speaker.Lock()
+		ctrl.Streamer = nil // the mixer drops it on the next read
+		speaker.Unlock()
+		return ctx.Err()
+	}
+}
+
 // PlayBackgroundMusic loads and plays an MP3 in a loop.
 func PlayBackgroundMusic(path string, volume float64) {
 	f, err := os.Open(path)
@@ -4,19 +4,46 @@ package game
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"gofigure/internal/llm"
 	"gofigure/internal/logger"
+	"gofigure/internal/tts"
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 )
 
 type Message struct {
-	Role      string    `json:"role,omitempty"`
-	Content   string    `json:"content,omitempty" json:"content,omitempty"`
-	Emotions  string    `json:"emotions,omitempty"`
-	Timestamp time.Time `json:"timestamp"`
+	Role      string      `json:"role,omitempty"`
+	Type      MessageType `json:"type,omitempty"`
+	Content   string      `json:"content,omitempty" json:"content,omitempty"`
+	Emotions  string      `json:"emotions,omitempty"`
+	Evidence  string      `json:"evidence,omitempty"` // name of the evidence shown, since save version 5
+	Timestamp time.Time   `json:"timestamp"`
 }
 
+// MessageType marks turns that are more than a question or an answer
+type MessageType string
+
+// question is what the detective asked in a question turn, without the
+// introduction added for the model
+func (m *Message) question() string {
+	if q, ok := strings.CutPrefix(m.Content, followUpPrefix); ok {
+		return q
+	}
+	return strings.TrimPrefix(m.Content, questionPrefix)
+}
+
+const (
+	// MessageEvidence is the detective presenting evidence. Its content is the
+	// evidence itself; the instructions for the model are added when it is sent.
+	MessageEvidence MessageType = "evidence"
+	// MessageSecret frees the character to confess the secret it holds
+	MessageSecret MessageType = "secret"
+)
+
 type TTS struct {
 	Engine string `json:"engine,omitempty"`
 	Model  string `json:"model,omitempty"`
@@ -29,33 +56,142 @@ type Character struct {
 	Knowledge   []string `json:"knowledge"`
 	Reliable    bool     `json:"reliable"`
 	TTS         []TTS    `json:"tts"`
+	Secrets     []Secret `json:"secrets,omitempty"`
 
-	Conversation []*Message
+	// Prosody overrides how this character delivers each emotion
+	Prosody map[string]tts.Prosody `json:"prosody,omitempty"`
+
+	Conversation []*Message `json:"-"`
 }
 
-func (c *Character) GetCharacterResponse(ctx context.Context, prompt string, llmClient llm.LLM) (*llm.CharacterReply, error) {
+// ReplyOptions controls how a character's reply is requested and read back
+type ReplyOptions struct {
+	// OnText receives the response text as it is generated, along with the
+	// emotion once that is known. The reply is only streamed when it is set.
+	OnText func(text, emotion string)
 
-	resp, err := llmClient.GenerateResponse(ctx, prompt)
-	if err != nil {
-		return nil, err
+	// Retries is how many times the model is re-prompted when its reply can't be parsed
+	Retries int
+
+	// JSONMode asks providers with a native JSON mode to use it
+	JSONMode bool
+}
+
+// GetCharacterResponse asks the llm for the character's next reply. Replies that
+// can't be parsed are re-requested with a correction, and if the model never
+// manages valid JSON whatever it said is used as the response.
+func (c *Character) GetCharacterResponse(ctx context.Context, messages []llm.Message, llmClient llm.LLM, opts ReplyOptions) (*llm.CharacterReply, error) {
+
+	chatOpts := llm.Options{}
+	if opts.JSONMode {
+		chatOpts.JSON = true
+		chatOpts.Schema = llm.CharacterReplySchema
 	}
 
-	var reply llm.CharacterReply
-	if err := json.Unmarshal([]byte(resp), &reply); err != nil {
-		logger.New().Warn(fmt.Sprintf("failed to unmarshal response. [response:%s, prompt:%s]", resp, prompt))
-		return nil, err
+	messages = slices.Clone(messages)
+
+	var resp string
+	var stream *llm.ReplyStream
+
+	for attempt := 0; attempt <= opts.Retries; attempt++ {
+		if attempt > 0 {
+			messages = append(messages,
+				llm.Message{Role: llm.RoleAssistant, Content: resp},
+				llm.Message{Role: llm.RoleUser, Content: llm.CorrectionPrompt})
+		}
+
+		var err error
+		for {
+			resp, stream, err = c.requestReply(ctx, messages, llmClient, chatOpts, opts.OnText)
+
+			relaxed, ok := relaxFormat(chatOpts, err)
+			if !ok {
+				break
+			}
+			logger.New().Warn(fmt.Sprintf("provider rejected the reply format, asking again without it. [error:%v, character:%s]", err, c.Name))
+			chatOpts = relaxed
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		reply, err := llm.ParseCharacterReply(resp)
+		if err == nil {
+			return reply, nil
+		}
+		logger.New().Warn(fmt.Sprintf("failed to parse response. [attempt:%d, error:%v, response:%s, character:%s]", attempt+1, err, resp, c.Name))
+
+		// the detective has already seen part of this reply, so asking
+		// again would repeat it; make do with what arrived instead
+		if reply, ok := stream.Reply(); ok {
+			return reply, nil
+		}
+	}
+
+	if reply, ok := llm.PlainTextReply(resp); ok {
+		return reply, nil
 	}
-	return &reply, nil
+	return nil, errors.New("model did not produce a usable reply")
+}
+
+// relaxFormat steps down the reply format after a provider has rejected the
+// request, from a JSON schema to plain JSON mode and then to no format at all
+func relaxFormat(opts llm.Options, err error) (llm.Options, bool) {
+	var se *llm.StatusError
+	if !errors.As(err, &se) || se.StatusCode != http.StatusBadRequest {
+		return opts, false
+	}
+
+	switch {
+	case len(opts.Schema) > 0:
+		opts.Schema = nil
+	case opts.JSON:
+		opts.JSON = false
+	default:
+		return opts, false
+	}
+	return opts, true
+}
+
+// requestReply sends the conversation to the llm, streaming it through a
+// ReplyStream when onText is set
+func (c *Character) requestReply(ctx context.Context, messages []llm.Message, llmClient llm.LLM, opts llm.Options, onText func(text, emotion string)) (string, *llm.ReplyStream, error) {
+	stream := &llm.ReplyStream{}
+
+	if onText == nil {
+		resp, err := llmClient.Chat(ctx, messages, opts)
+		return resp, stream, err
+	}
+
+	stream.OnText = func(text string) {
+		emotion, _ := stream.Emotion()
+		onText(text, emotion)
+	}
+	resp, err := llmClient.ChatStream(ctx, messages, opts, stream.Write)
+	return resp, stream, err
 }
 
 // AskQuestion using Ollama client for character interaction
 func (c *Character) AskQuestion(ctx context.Context, question string, murder Murder, llmClient llm.LLM) (*llm.CharacterReply, error) {
+	return c.AskQuestionWith(ctx, question, murder, llmClient, ReplyOptions{})
+}
 
+// AskQuestionWith is AskQuestion with control over streaming and reply repair
+func (c *Character) AskQuestionWith(ctx context.Context, question string, murder Murder, llmClient llm.LLM, opts ReplyOptions) (*llm.CharacterReply, error) {
 	c.addQuestion(question, murder)
+	return c.reply(ctx, llmClient, opts)
+}
 
-	prompt := c.serialiseConversation()
+// PresentEvidence confronts the character with evidence. How they react
+// depends on what they know, what they are hiding and how honest they are.
+func (c *Character) PresentEvidence(ctx context.Context, ev *Evidence, murder Murder, llmClient llm.LLM, opts ReplyOptions) (*llm.CharacterReply, error) {
+	c.addEvidence(ev, murder)
+	return c.reply(ctx, llmClient, opts)
+}
 
-	resp, err := c.GetCharacterResponse(ctx, prompt, llmClient)
+// reply answers the latest turn of the conversation and records the answer
+func (c *Character) reply(ctx context.Context, llmClient llm.LLM, opts ReplyOptions) (*llm.CharacterReply, error) {
+	resp, err := c.GetCharacterResponse(ctx, c.chatMessages(), llmClient, opts)
 	if err != nil {
 		logger.New().WithError(err).Warn("could not generate character response")
 		return &llm.CharacterReply{}, err
@@ -64,7 +200,7 @@ func (c *Character) AskQuestion(ctx context.Context, question string, murder Mur
 	c.Conversation = append(c.Conversation, &Message{
 
 		// openai supperted types:  ['system', 'assistant', 'user', 'function', 'tool', and 'developer']",
-		Role:      "assistant",
+		Role:      llm.RoleAssistant,
 		Content:   resp.Response,
 		Emotions:  resp.Emotion,
 		Timestamp: time.Now(),
@@ -73,27 +209,95 @@ func (c *Character) AskQuestion(ctx context.Context, question string, murder Mur
 	return resp, nil
 }
 
+// how a question is introduced to the model, first and from then on
+const (
+	questionPrefix = "Detective's question: "
+	followUpPrefix = "Detective's follow up question: "
+)
+
 func (c *Character) addQuestion(question string, murder Murder) {
+	latest := followUpPrefix + question
+	if !slices.ContainsFunc(c.Conversation, func(msg *Message) bool { return msg.Role == llm.RoleUser }) {
+		latest = questionPrefix + question
+	}
+	if c.IsInitialMessage() {
+		c.startConversation(murder)
+	}
+
+	c.Conversation = append(c.Conversation, &Message{Role: llm.RoleUser, Content: latest, Timestamp: time.Now()})
+}
+
+func (c *Character) addEvidence(ev *Evidence, murder Murder) {
+	if c.IsInitialMessage() {
+		c.startConversation(murder)
+	}
+
+	c.Conversation = append(c.Conversation, &Message{
+		Role:      llm.RoleUser,
+		Type:      MessageEvidence,
+		Content:   fmt.Sprintf("%s - %s", ev.Name, ev.Description),
+		Evidence:  ev.Name,
+		Timestamp: time.Now(),
+	})
+}
+
+// allowSecret tells the character they may now give up a secret
+func (c *Character) allowSecret(secret Secret, murder Murder) {
+	if c.IsInitialMessage() {
+		c.startConversation(murder)
+	}
+
+	c.Conversation = append(c.Conversation, &Message{
+		Role:      llm.RoleSystem,
+		Type:      MessageSecret,
+		Content:   secret.Text,
+		Timestamp: time.Now(),
+	})
+}
+
+// startConversation opens the conversation with the character's brief
+func (c *Character) startConversation(murder Murder) {
+	view := murder.ViewFor(c)
+
 	reliabilityNote := "You are generally truthful and helpful."
 	if !c.Reliable {
 		reliabilityNote = "You might hide some facts, be evasive, or provide misleading information. Stay in character."
 	}
 
-	latest := fmt.Sprintf("Detective's follow up question: %s", question)
+	var guarded, locked []string
+	for _, secret := range c.Secrets {
+		if secret.Unlock != nil {
+			locked = append(locked, secret.Text)
+		} else {
+			guarded = append(guarded, secret.Text)
+		}
+	}
 
-	if c.IsInitialMessage() {
-		scenario := fmt.Sprintf(`You are roleplaying as %s in a murder mystery game.
+	secretsNote := "- You have nothing in particular to hide"
+	if len(guarded) > 0 {
+		secretsNote = fmt.Sprintf("- Secrets you guard: %s\n- Never volunteer a secret. Deflect, change the subject or lie (if that fits your personality) unless the detective presents you with something you cannot deny", strings.Join(guarded, "; "))
+	}
+	if len(locked) > 0 {
+		note := fmt.Sprintf("- Secrets you will not admit under any pressure until you are told you may: %s", strings.Join(locked, "; "))
+		if len(guarded) == 0 {
+			secretsNote = note
+		} else {
+			secretsNote += "\n" + note
+		}
+	}
+
+	scenario := fmt.Sprintf(`You are roleplaying as %s in a murder mystery game.
 
 CHARACTER PROFILE:
 - Name: %s
 - Personality: %s
 - %s
 
+SECRETS:
+%s
+
 MURDER SCENARIO:
-- Victim found in: %s
-- Murder weapon: %s  
-- Actual killer: %s
-- Your knowledge about the case: %v
+%s
 
 INSTRUCTIONS:
 - Stay completely in character
@@ -101,36 +305,64 @@ INSTRUCTIONS:
 - Keep responses concise but engaging
 - Don't break character or mention this is a game
 - If you don't know something, say so in character
-- Give the character response and derive their emotional state
-- Reply in this JSON structure {"response": string, "emotion": string}
-
-Detective's question: "%s"
+- Derive the character's emotional state, then give their response
+- Reply in this JSON structure {"emotion": string, "response": string}`,
+		c.Name, c.Name, c.Personality, reliabilityNote,
+		secretsNote,
+		view)
 
-Your response as %s:`,
-			c.Name, c.Name, c.Personality, reliabilityNote,
-			murder.Location, murder.Weapon, murder.Killer, c.Knowledge,
-			question, c.Name)
+	c.Conversation = []*Message{
+		{Role: llm.RoleSystem, Content: scenario, Timestamp: time.Now()},
+	}
+}
 
-		c.Conversation = []*Message{
-			{Role: "system", Content: fmt.Sprintf("%s", scenario), Timestamp: time.Now()},
-		}
+// evidencePrompt asks the model to react to evidence the way this character would
+func (c *Character) evidencePrompt(evidence string) string {
+	honesty := "You are honest about what it means to you, even if that is uncomfortable."
+	if !c.Reliable {
+		honesty = "You may try to explain it away, but any lie has to fit what the evidence plainly shows."
+	}
 
-		latest = fmt.Sprintf("Detective's question: %s", question)
+	cornered := "If it contradicts something you said earlier, correct yourself."
+	if len(c.Secrets) > 0 {
+		cornered = "If it exposes one of your secrets or contradicts something you said earlier, you can no longer simply deny it: crack, admit that part, or give a desperate explanation, as your personality dictates."
 	}
 
-	c.Conversation = append(c.Conversation, &Message{Role: "user", Content: latest, Timestamp: time.Now()})
+	return fmt.Sprintf(`The detective shows you a piece of evidence: %s
+
+React as %s would on seeing it:
+- If it relates to something you know, recognise it and say what it means to you
+- %s
+- %s
+- If it means nothing to you, say so in character
+- Reply in this JSON structure {"emotion": string, "response": string}`,
+		evidence, c.Name, honesty, cornered)
 }
 
 func (c *Character) IsInitialMessage() bool {
 	return len(c.Conversation) == 0
 }
 
-func (c *Character) serialiseConversation() string {
-	s, err := json.Marshal(c.Conversation)
-	if err != nil {
-		logger.New().Error(err.Error())
-		return ""
+// chatMessages converts the conversation into chat turns. The character's own
+// replies are sent back in the JSON structure the model was asked to use,
+// so it keeps answering that way.
+func (c *Character) chatMessages() []llm.Message {
+	messages := make([]llm.Message, 0, len(c.Conversation))
+	for _, msg := range c.Conversation {
+		content := msg.Content
+		switch msg.Type {
+		case MessageEvidence:
+			content = c.evidencePrompt(msg.Content)
+		case MessageSecret:
+			content = fmt.Sprintf("The detective has got close to one of your secrets. From now on you may admit it when pressed, in your own way and as your personality dictates: %s", msg.Content)
+		}
+		if msg.Role == llm.RoleAssistant {
+			reply, err := json.Marshal(llm.CharacterReply{Response: msg.Content, Emotion: msg.Emotions})
+			if err == nil {
+				content = string(reply)
+			}
+		}
+		messages = append(messages, llm.Message{Role: msg.Role, Content: content})
 	}
-
-	return string(s)
+	return messages
 }
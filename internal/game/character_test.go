@@ -0,0 +1,245 @@
+package game
+
+import (
+	"context"
+	"gofigure/internal/llm"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeLLM records every prompt it is sent and always answers in character,
+// or with reply when that is set, or with whatever answer makes of the prompt
+type fakeLLM struct {
+	prompts []string
+	reply   string
+	answer  func(prompt string) string
+}
+
+func (f *fakeLLM) GenerateResponse(_ context.Context, prompt string) (string, error) {
+	f.prompts = append(f.prompts, prompt)
+	if f.answer != nil {
+		return f.answer(prompt), nil
+	}
+	if f.reply != "" {
+		return f.reply, nil
+	}
+	return `{"response": "I was in my room all evening, detective.", "emotion": "calm"}`, nil
+}
+
+// Chat records the whole conversation as a single prompt
+func (f *fakeLLM) Chat(ctx context.Context, messages []llm.Message, _ llm.Options) (string, error) {
+	var prompt strings.Builder
+	for _, msg := range messages {
+		prompt.WriteString(msg.Role + ": " + msg.Content + "\n")
+	}
+	return f.GenerateResponse(ctx, prompt.String())
+}
+
+func (f *fakeLLM) ChatStream(ctx context.Context, messages []llm.Message, opts llm.Options, onChunk func(string)) (string, error) {
+	resp, err := f.Chat(ctx, messages, opts)
+	if err == nil {
+		onChunk(resp)
+	}
+	return resp, err
+}
+
+func (f *fakeLLM) IsModelAvailable(_ context.Context) error {
+	return nil
+}
+
+func testMurder() Murder {
+	return Murder{
+		Title:    "The Greenhouse Affair",
+		Intro:    "The gardener was found dead among the orchids.",
+		Victim:   "Old Tom",
+		Killer:   "Vera Holt",
+		Weapon:   "Pruning Shears",
+		Location: "Greenhouse",
+		Motive:   "Tom was about to expose her forged will",
+		Characters: []Character{
+			{Name: "Vera Holt", Personality: "Cold", Knowledge: []string{"Was reading in the parlour"}, Reliable: false},
+			{Name: "Percy Lane", Personality: "Jumpy", Knowledge: []string{"Heard glass break at midnight"}, Reliable: true},
+			{Name: "Mrs. Oduya", Personality: "Kind", Knowledge: []string{"Made cocoa for everyone at eleven"}, Reliable: true, Secrets: []Secret{{Text: "Reads other people's letters"}}},
+		},
+	}
+}
+
+func askEveryone(t *testing.T, m Murder) map[string]string {
+	t.Helper()
+
+	prompts := map[string]string{}
+	for i := range m.Characters {
+		llm := &fakeLLM{}
+		char := &m.Characters[i]
+
+		if _, err := char.AskQuestion(context.Background(), "where were you at midnight?", m, llm); err != nil {
+			t.Fatalf("%s: AskQuestion failed: %v", char.Name, err)
+		}
+		if len(llm.prompts) != 1 {
+			t.Fatalf("%s: expected 1 prompt, got %d", char.Name, len(llm.prompts))
+		}
+		prompts[char.Name] = llm.prompts[0]
+	}
+	return prompts
+}
+
+func TestSolutionOnlyInKillerPrompt(t *testing.T) {
+	m := testMurder()
+	prompts := askEveryone(t, m)
+
+	for name, prompt := range prompts {
+		isKiller := name == m.Killer
+		for _, secret := range []string{m.Killer, m.Weapon, m.Motive} {
+			if strings.Contains(prompt, secret) != isKiller {
+				t.Errorf("%s: prompt contains %q = %t, want %t", name, secret, !isKiller, isKiller)
+			}
+		}
+	}
+}
+
+// TestBundledMysteriesDoNotLeakSolution checks the real cases. Witnesses may
+// legitimately name the killer in their own knowledge, so everything a
+// character is allowed to know is removed before looking for the solution.
+func TestBundledMysteriesDoNotLeakSolution(t *testing.T) {
+	files, err := filepath.Glob("../../data/mysteries/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no bundled mysteries found: %v", err)
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			m, err := loadMystery(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			prompts := askEveryone(t, m)
+			killer := m.findCharacterByName(m.Killer)
+
+			for _, char := range m.Characters {
+				if char.Name == killer.Name {
+					if !strings.Contains(prompts[char.Name], m.Motive) {
+						t.Errorf("%s is the killer but was not told their motive", char.Name)
+					}
+					continue
+				}
+
+				prompt := prompts[char.Name]
+				allowed := append([]string{m.Intro, char.Personality}, char.Knowledge...)
+				allowed = append(allowed, char.secretTexts()...)
+				for _, s := range allowed {
+					prompt = strings.ReplaceAll(prompt, s, "")
+				}
+
+				if strings.Contains(prompt, m.Killer) {
+					t.Errorf("%s: prompt names the killer %q", char.Name, m.Killer)
+				}
+				if strings.Contains(prompt, m.Motive) {
+					t.Errorf("%s: prompt reveals the motive", char.Name)
+				}
+			}
+		})
+	}
+}
+
+// formatRejectingLLM answers with a 400 while any of the formats it can't
+// handle are asked for, recording the options of every request
+type formatRejectingLLM struct {
+	fakeLLM
+	rejectSchema, rejectJSON bool
+	requests                 []llm.Options
+}
+
+func (f *formatRejectingLLM) Chat(ctx context.Context, messages []llm.Message, opts llm.Options) (string, error) {
+	f.requests = append(f.requests, opts)
+	if (f.rejectSchema && len(opts.Schema) > 0) || (f.rejectJSON && opts.JSON) {
+		return "", &llm.StatusError{Provider: "test", StatusCode: 400, Message: "response_format is not supported"}
+	}
+	return f.fakeLLM.Chat(ctx, messages, opts)
+}
+
+func TestReplyFormatFallsBackWhenRejected(t *testing.T) {
+	tests := []struct {
+		name         string
+		rejectSchema bool
+		rejectJSON   bool
+		wantRequests int
+		wantJSON     bool
+		wantSchema   bool
+	}{
+		{name: "accepted", wantRequests: 1, wantJSON: true, wantSchema: true},
+		{name: "no schemas", rejectSchema: true, wantRequests: 2, wantJSON: true},
+		{name: "no json mode", rejectSchema: true, rejectJSON: true, wantRequests: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &formatRejectingLLM{rejectSchema: tt.rejectSchema, rejectJSON: tt.rejectJSON}
+			char := &testMurder().Characters[1]
+
+			reply, err := char.GetCharacterResponse(context.Background(), []llm.Message{{Role: llm.RoleUser, Content: "hello"}}, client, ReplyOptions{JSONMode: true})
+			if err != nil {
+				t.Fatalf("GetCharacterResponse: %v", err)
+			}
+			if reply.Emotion != "calm" {
+				t.Errorf("got reply %+v", reply)
+			}
+
+			if len(client.requests) != tt.wantRequests {
+				t.Fatalf("sent %d requests, want %d", len(client.requests), tt.wantRequests)
+			}
+			last := client.requests[len(client.requests)-1]
+			if last.JSON != tt.wantJSON || (len(last.Schema) > 0) != tt.wantSchema {
+				t.Errorf("answered with json %v, schema %v; want json %v, schema %v", last.JSON, len(last.Schema) > 0, tt.wantJSON, tt.wantSchema)
+			}
+		})
+	}
+}
+
+func TestReplyGivesUpOnOtherErrors(t *testing.T) {
+	for _, code := range []int{401, 404, 500} {
+		err := &llm.StatusError{Provider: "test", StatusCode: code}
+		if _, ok := relaxFormat(llm.Options{JSON: true, Schema: llm.CharacterReplySchema}, err); ok {
+			t.Errorf("status %d relaxed the reply format", code)
+		}
+	}
+
+	if _, ok := relaxFormat(llm.Options{}, &llm.StatusError{StatusCode: 400}); ok {
+		t.Error("relaxed a request that asked for no format")
+	}
+}
+
+func TestPresentEvidence(t *testing.T) {
+	m := testMurder()
+	ev := &Evidence{Name: "Torn letter", Description: "Half a page in Vera's hand"}
+	char := &m.Characters[2]
+	client := &fakeLLM{}
+
+	if _, err := char.PresentEvidence(context.Background(), ev, m, client, ReplyOptions{}); err != nil {
+		t.Fatalf("PresentEvidence: %v", err)
+	}
+	if _, err := char.AskQuestion(context.Background(), "Who wrote it?", m, client); err != nil {
+		t.Fatalf("AskQuestion: %v", err)
+	}
+
+	// the conversation keeps the evidence itself, and the instructions are added when it is sent
+	shown := char.Conversation[1]
+	if shown.Type != MessageEvidence || shown.Content != "Torn letter - Half a page in Vera's hand" {
+		t.Errorf("filed %+v, want the evidence", shown)
+	}
+
+	for i, prompt := range client.prompts {
+		if !strings.Contains(prompt, "The detective shows you a piece of evidence: Torn letter - Half a page in Vera's hand") {
+			t.Errorf("prompt %d doesn't expand the evidence:\n%s", i, prompt)
+		}
+		if !strings.Contains(prompt, "exposes one of your secrets") {
+			t.Errorf("prompt %d doesn't warn a character with secrets:\n%s", i, prompt)
+		}
+	}
+
+	messages := char.chatMessages()
+	if last := messages[len(messages)-1]; last.Role != llm.RoleAssistant || !strings.Contains(last.Content, `"response":"I was in my room all evening, detective."`) {
+		t.Errorf("reply sent back as %+v, want the JSON the model answered in", last)
+	}
+}
@@ -18,7 +18,18 @@ import (
 )
 
 type Engine struct {
-	murder Murder
+	murder      Murder
+	mysteryFile string
+
+	// investigation progress, persisted in save files
+	accusations []string
+	evidence    []string // names of the evidence found so far
+	room        string   // where the detective is, in a mystery with locations
+	unlocked    []string // keys of the secrets characters may now confess
+	elapsed     time.Duration
+	startedAt   time.Time
+	resumed     bool
+	notebook    *Notebook
 
 	tts    tts.Tts
 	sst    sst.Sst
@@ -31,6 +42,9 @@ type Engine struct {
 	showHints     bool
 
 	scanner *bufio.Scanner
+
+	// ambience is the current room's sound, while it plays
+	ambience *speechQueue
 }
 
 func NewEngine(cfg *config.Config) (*Engine, error) {
@@ -45,31 +59,21 @@ func NewEngine(cfg *config.Config) (*Engine, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	var t tts.Tts
-	t = tts.NewDummyTts()
-
-	if cfg.Tts.Enabled {
-		t, err = tts.NewGoogleTTS(ctx)
-		if err != nil {
-			logger.New().WithError(err).Error("failed to create tts TTS client")
-			t = tts.NewDummyTts()
-		} else {
-			logger.New().Debug("google tts client created")
-		}
+	t, err := tts.NewTts(ctx, &cfg.Tts)
+	if err != nil {
+		logger.New().WithError(err).Error("failed to create TTS client, using dummy")
+		t = tts.NewDummyTts()
 	}
 
 	// Initialize SST
-	var s sst.Sst
-	s = sst.NewDummySST()
-
-	if cfg.Sst.Enabled && cfg.Sst.Provider == "google" {
-		s, err = sst.NewGoogleSST(ctx, cfg.Sst.LanguageCode, cfg.Sst.SampleRate)
-		if err != nil {
-			logger.New().WithError(err).Error("failed to create Google SST client, using dummy")
-			s = sst.NewDummySST()
-		} else {
-			logger.New().Debug("google sst client created")
-		}
+	s, err := sst.NewSst(ctx, &cfg.Sst)
+	if err != nil && len(cfg.Sst.AudioInput) > 0 {
+		// recordings can only be heard by a working recognizer
+		return nil, fmt.Errorf("failed to create %s SST client for the recorded audio: %w", cfg.Sst.Provider, err)
+	}
+	if err != nil {
+		logger.New().WithError(err).Error(fmt.Sprintf("failed to create %s SST client, using dummy", cfg.Sst.Provider))
+		s = sst.NewDummySST()
 	}
 
 	// play background music
@@ -81,6 +85,7 @@ func NewEngine(cfg *config.Config) (*Engine, error) {
 		llm:           llmClient,
 		logger:        logger.New(),
 		config:        cfg,
+		notebook:      NewNotebook(),
 		showResponses: false,
 		useMicInput:   true,
 	}, nil
@@ -98,15 +103,40 @@ func (e *Engine) WithMurder(filename string) *Engine {
 		return e
 	}
 	e.murder = m
+	e.mysteryFile = filename
+	e.refreshPhraseHints()
+	return e
+}
+
+func (e *Engine) WithSave(slot string) *Engine {
+	if err := e.loadGame(slot); err != nil {
+		e.logger.WithError(err).Error("failed to load saved game")
+	}
 	return e
 }
 
+// refreshPhraseHints tells the recognizer which names to listen out for in
+// the loaded mystery
+func (e *Engine) refreshPhraseHints() {
+	e.sst.SetPhraseHints(append(e.murder.phraseHints(), commandWords...))
+}
+
 func (e *Engine) WithMicInput(useMic bool) *Engine {
 	e.useMicInput = useMic && e.config.Sst.Enabled
+
+	// the dummy stands in for a provider that failed to start, and hears nothing
+	if _, dummy := e.sst.(*sst.DummySST); e.useMicInput && dummy {
+		e.logger.Warn("speech recognition is unavailable, type your questions instead")
+		e.useMicInput = false
+	}
 	return e
 }
 
 func (e *Engine) Start() error {
+	if len(e.murder.Characters) == 0 {
+		return fmt.Errorf("no mystery loaded")
+	}
+
 	// Check if Ollama model is available
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -118,7 +148,15 @@ func (e *Engine) Start() error {
 
 	e.logger.Debug("llm connection verified")
 
-	welcomeMessage := fmt.Sprintf("Welcome Detective! You are investigating: %s", e.murder.Title)
+	e.startedAt = time.Now()
+
+	if e.resumed {
+		e.logger.Info(fmt.Sprintf("🔍 Welcome back Detective! Resuming: %s (%s elapsed)",
+			e.murder.Title, e.elapsed.Round(time.Second)))
+		return e.gameLoop()
+	}
+
+	welcomeMessage := e.murder.welcomeMessage()
 	e.logger.Info(fmt.Sprintf("🔍 %s", welcomeMessage))
 
 	// Read the introduction aloud if TTS is enabled and narrator TTS model is configured
@@ -136,20 +174,27 @@ func (e *Engine) Start() error {
 
 func (e *Engine) gameLoop() error {
 
+	// voice mode reads stdin too, to confirm what it heard, so everything
+	// goes through the one buffered scanner
+	e.scanner = bufio.NewScanner(os.Stdin)
+
 	if e.useMicInput {
 		e.logger.Info("🎙️ Microphone input enabled for interviews!")
 	} else {
 		e.logger.Info("Type 'help' for available commands.")
-		e.scanner = bufio.NewScanner(os.Stdin)
+	}
+
+	if e.currentLocation() != nil {
+		e.look()
 	}
 
 	for {
 		prompt := e.getPrompt()
-		parts := strings.SplitN(prompt, " ", 2)
-
-		if len(parts) == 0 {
+		if prompt == "" {
+			// nothing heard, or nothing typed
 			continue
 		}
+		parts := strings.SplitN(prompt, " ", 2)
 
 		switch strings.ToLower(parts[0]) {
 		case "help":
@@ -174,6 +219,84 @@ func (e *Engine) gameLoop() error {
 				return nil // Game won
 			}
 
+		case "evidence":
+			e.listEvidence()
+
+		case "go":
+			if len(parts) < 2 {
+				fmt.Println("Usage: go <room>")
+				continue
+			}
+			e.goTo(parts[1])
+
+		case "look":
+			e.look()
+
+		case "map":
+			e.showMap()
+
+		case "examine":
+			if len(parts) < 2 {
+				if here := e.currentLocation(); here != nil {
+					e.search(here.Name)
+					continue
+				}
+				fmt.Println("Usage: examine <item or place>")
+				fmt.Printf("Places to search: %s\n", strings.Join(e.murder.places(), ", "))
+				continue
+			}
+			e.examine(parts[1])
+
+		case "note":
+			if len(parts) < 2 {
+				fmt.Println("Usage: note <text>")
+				continue
+			}
+			e.notebook.AddNote(parts[1])
+			fmt.Println("📝 Noted.")
+
+		case "notes":
+			if len(parts) < 2 {
+				e.showNotes(e.notebook.Entries(), "Your notebook is empty.")
+				continue
+			}
+			char := e.findCharacter(parts[1])
+			if char == nil {
+				fmt.Printf("No character named '%s' found. Enter 'list' command to see available characters.\n", parts[1])
+				continue
+			}
+			e.showNotes(e.notebook.ForCharacter(char.Name), fmt.Sprintf("Nothing noted about %s yet.", char.Name))
+
+		case "search":
+			if len(parts) < 2 {
+				fmt.Println("Usage: search <text>")
+				continue
+			}
+			e.showNotes(e.notebook.Search(parts[1]), fmt.Sprintf("Nothing in your notebook mentions '%s'.", parts[1]))
+
+		case "save":
+			if len(parts) < 2 {
+				fmt.Println("Usage: save <slot>")
+				continue
+			}
+			if err := e.saveGame(parts[1]); err != nil {
+				e.logger.WithError(err).Error("failed to save game")
+				continue
+			}
+			fmt.Printf("💾 Investigation saved to slot '%s'\n", parts[1])
+
+		case "load":
+			if len(parts) < 2 {
+				fmt.Println("Usage: load <slot>")
+				continue
+			}
+			if err := e.loadGame(parts[1]); err != nil {
+				e.logger.WithError(err).Error("failed to load game")
+				continue
+			}
+			fmt.Printf("📂 Resumed '%s' from slot '%s' (%s elapsed)\n",
+				e.murder.Title, parts[1], e.elapsed.Round(time.Second))
+
 		case "quit", "exit":
 			fmt.Println("Goodbye detective.")
 			return nil
@@ -190,12 +313,31 @@ func (e *Engine) showHelp() {
 	fmt.Println("  list                           - List all characters")
 	fmt.Println("  interview <character>          - Interview a character")
 	fmt.Println("  accuse <name> <weapon> <location> - Make your final accusation")
+	fmt.Println("  examine <item or place>        - Look closely at evidence, or search a place for it")
+	fmt.Println("  evidence                       - List the evidence you have found")
+	if len(e.murder.Locations) > 0 {
+		fmt.Println("  go <room>                      - Walk to another room")
+		fmt.Println("  look                           - Look around the room you're in")
+		fmt.Println("  map                            - Show every room and who is in it")
+	}
+	fmt.Println("  note <text>                    - Write a note in your notebook")
+	fmt.Println("  notes [character]              - Read your notebook, optionally for one character")
+	fmt.Println("  search <text>                  - Search your notebook")
+	fmt.Println("  save <slot>                    - Save the investigation")
+	fmt.Println("  load <slot>                    - Resume a saved investigation")
 	fmt.Println("  quit/exit                      - Exit the game")
+	fmt.Println("\nDuring an interview:")
+	fmt.Println("  show <evidence>                - Confront the character with evidence you have found")
+	fmt.Println("  exit                           - End the interview")
 
 	if e.useMicInput {
 		fmt.Println("\n🎙️ Voice Mode Enabled:")
 		fmt.Println("  • Interviews automatically use voice input")
-		fmt.Println("  • Press ENTER to record questions")
+		if e.config.Sst.Mode == sst.ModeVAD {
+			fmt.Println("  • Just start talking: your question ends when you pause")
+		} else {
+			fmt.Println("  • Press ENTER to record questions")
+		}
 		fmt.Println("  • Type 'text' during interviews to switch to typing")
 		fmt.Println("  • Type 'voice' during text mode to switch back")
 
@@ -205,7 +347,24 @@ func (e *Engine) showHelp() {
 func (e *Engine) listCharacters() {
 	fmt.Println("\nCharacters in this mystery:")
 	for _, char := range e.murder.Characters {
-		fmt.Printf("  • %s (%s)\n", char.Name, char.Personality)
+		if room := e.murder.locationOf(&char); room != "" {
+			fmt.Printf("  • %s (%s) - %s\n", char.Name, char.Personality, room)
+		} else {
+			fmt.Printf("  • %s (%s)\n", char.Name, char.Personality)
+		}
+	}
+	fmt.Println()
+}
+
+func (e *Engine) showNotes(entries []*NotebookEntry, empty string) {
+	if len(entries) == 0 {
+		fmt.Println(empty)
+		return
+	}
+
+	fmt.Println("\n📓 Detective's notebook:")
+	for _, entry := range entries {
+		fmt.Printf("  %s\n", entry)
 	}
 	fmt.Println()
 }
@@ -216,8 +375,15 @@ func (e *Engine) interviewCharacter(charName string) {
 		fmt.Printf("No character named '%s' found. Enter 'list' command to see available characters.\n", charName)
 		return
 	}
+	if !e.isPresent(char) {
+		fmt.Printf("%s isn't here. You'll find them in the %s.\n", char.Name, e.murder.locationOf(char))
+		return
+	}
 
 	fmt.Printf("\n🎭 You are now interviewing %s\n", char.Name)
+	if len(e.murder.Evidence) > 0 {
+		fmt.Println("Type 'show <evidence>' to confront them with something you have found.")
+	}
 
 	if e.showHints {
 		fmt.Printf("Personality: %s\n", char.Personality)
@@ -230,27 +396,32 @@ func (e *Engine) getPrompt() string {
 
 	// mic prompt
 	if e.useMicInput {
-		prompt, err := e.getVoiceInput()
+		heard, err := e.getVoiceInput()
+		if errors.Is(err, sst.ErrNoMoreAudio) {
+			fmt.Println("🎙️ No more recorded questions, switching to typing")
+			e.useMicInput = false
+			return ""
+		}
 		if err != nil {
 			fmt.Printf("Voice input failed: %v\n", err)
 			return ""
 		}
-		s := strings.TrimSpace(strings.ToLower(prompt))
-		fmt.Printf("[captured] %s\n", s)
+		s := e.chooseTranscript(heard)
+		if s != "" {
+			fmt.Printf("[captured] %s\n", s)
+		}
 		return s
 	}
 
 	// text prompt
-	for {
-		fmt.Print("> ")
-		if !e.scanner.Scan() {
-			break
-		}
-
-		return strings.TrimSpace(strings.ToLower(e.scanner.Text()))
+	fmt.Print("> ")
+	if !e.scanner.Scan() {
+		// stdin has closed, e.g. at the end of a scripted playthrough
+		fmt.Println()
+		return "exit"
 	}
 
-	return ""
+	return strings.TrimSpace(strings.ToLower(e.scanner.Text()))
 }
 
 func (e *Engine) startInterview(char *Character) {
@@ -266,168 +437,219 @@ func (e *Engine) startInterview(char *Character) {
 			continue
 		}
 
+		// anything else starting with "show", like "show me your hands", is a question
+		if target, ok := strings.CutPrefix(prompt, "show "); ok {
+			if ev := e.evidenceToShow(target); ev != nil {
+				e.presentEvidence(char, ev)
+				continue
+			}
+		}
+
 		e.processQuestion(char, prompt)
 	}
 }
 
 func (e *Engine) processQuestion(char *Character, question string) {
+	e.unlockSecrets(char, question, nil)
+	e.converse(char, question, func(ctx context.Context, opts ReplyOptions) (*llmpkg.CharacterReply, error) {
+		return char.AskQuestionWith(ctx, question, e.murder, e.llm, opts)
+	})
+}
+
+// evidenceToShow is the evidence the detective has found that target names,
+// or nil if there is none
+func (e *Engine) evidenceToShow(target string) *Evidence {
+	if ev := e.findFoundEvidence(target, true); ev != nil {
+		return ev
+	}
+	return e.findFoundEvidence(target, false)
+}
+
+// presentEvidence confronts a character with evidence the detective has found
+func (e *Engine) presentEvidence(char *Character, ev *Evidence) {
+	fmt.Printf("🧾 You show %s the %s.\n", char.Name, ev.Name)
+	e.unlockSecrets(char, "", ev)
+	e.converse(char, fmt.Sprintf("[shows the %s]", ev.Name), func(ctx context.Context, opts ReplyOptions) (*llmpkg.CharacterReply, error) {
+		return char.PresentEvidence(ctx, ev, e.murder, e.llm, opts)
+	})
+}
+
+// converse gets a character's reply to the detective, printing and speaking
+// it as it arrives, and files what was said
+func (e *Engine) converse(char *Character, question string, ask func(ctx context.Context, opts ReplyOptions) (*llmpkg.CharacterReply, error)) {
 	e.logger.Debug("🤔 Thinking...")
 
-	// Use different timeout based on LLM provider
-	timeout := time.Duration(e.config.Ollama.Timeout) * time.Second
-	if e.config.LLM.Provider == "openai" {
-		timeout = time.Duration(e.config.OpenAI.Timeout) * time.Second
+	showText := !e.useMicInput || e.showResponses
+	speech := newSpeechQueue(e.tts, e.findTtsModel(char), char.Prosody)
+
+	// print and speak the reply as it streams in
+	started := false
+	onText := func(text, emotion string) {
+		if showText {
+			if !started {
+				e.logger.Character(char.Name, fmt.Sprintf("\r%s: [emotion:%s] ", char.Name, emotion))
+			}
+			fmt.Print(text)
+		}
+		started = true
+		speech.add(text, emotion)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), e.llmTimeout())
 
-	answer, err := char.AskQuestion(ctx, question, e.murder, e.llm)
+	answer, err := ask(ctx, ReplyOptions{
+		OnText:   onText,
+		Retries:  e.config.LLM.ReplyRetries,
+		JSONMode: e.config.LLM.JSONMode,
+	})
 	cancel()
 
+	// a reply recovered from plain text never streamed, so show it now
+	if err == nil && !started {
+		onText(answer.Response, answer.Emotion)
+	}
+
+	if started && showText {
+		fmt.Println()
+	}
+
 	if err != nil {
+		speech.cancel()
 		e.logger.WithError(err).Error("Failed to get character response")
 		fmt.Printf("\n%s seems distracted and doesn't respond clearly.\n", char.Name)
 		return
 	}
 
-	ctx, _ = context.WithTimeout(context.Background(),
-		time.Duration(e.config.Ollama.Timeout)*time.Second)
+	e.notebook.RecordReply(char.Name, question, answer)
+	e.revealFromInterview(char, question, answer.Response)
+	if e.config.Game.ExtractClues {
+		go e.fileClues(e.notebook, char.Name, question, answer.Response)
+	}
 
-	if err = e.tts.Speak(ctx, answer.Response, answer.Emotion, e.findTtsModel(char)); err != nil {
-		logger.New().WithError(err).Error("character has lost their voice")
+	// wait for the character to finish speaking before taking the next question
+	speech.finish()
+}
+
+// fileClues extracts factual claims from an answer and files them in the
+// notebook the answer was recorded in. Loading a game replaces the notebook,
+// so clues that arrive after a load are filed in the abandoned one.
+func (e *Engine) fileClues(notebook *Notebook, character, question, answer string) {
+	ctx, cancel := context.WithTimeout(context.Background(), e.llmTimeout())
+	defer cancel()
+
+	clues, err := extractClues(ctx, e.llm, character, question, answer)
+	if err != nil {
+		e.logger.Debug(fmt.Sprintf("[notebook] failed to extract clues: %v", err))
+		return
 	}
 
-	if !e.useMicInput || e.showResponses {
-		e.logger.Character(char.Name, fmt.Sprintf("\r%s: [emotion:%s] %s\n", char.Name, answer.Emotion, answer.Response))
+	for _, clue := range clues {
+		notebook.AddClue(character, clue)
 	}
+	e.logger.Debug(fmt.Sprintf("[notebook] filed %d clues from %s", len(clues), character))
+}
+
+// llmTimeout allows for retries and fallbacks across the provider chain
+func (e *Engine) llmTimeout() time.Duration {
+	return llmpkg.Timeout(e.config)
 }
 
-func (e *Engine) getVoiceInput() (string, error) {
-	fmt.Println("🎙️ Press ENTER to start recording...")
-	fmt.Scanln()
+// questionTimeout is the longest the detective can talk for in one go. It is a
+// var so tests don't have to wait for it.
+var questionTimeout = 30 * time.Second
+
+func (e *Engine) getVoiceInput() (sst.Result, error) {
+	// in vad mode the provider starts and stops on the detective's voice, and
+	// recordings start straight away and stop when they run out
+	handsFree := e.config.Sst.Mode == sst.ModeVAD || len(e.config.Sst.AudioInput) > 0
+
+	if !handsFree {
+		fmt.Println("🎙️ Press ENTER to start recording...")
+		fmt.Scanln()
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// the session runs until the provider closes the channel; the question
+	// timeout stops it, so what was captured is still transcribed
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	log := logger.New()
-	transcriptChan, err := e.sst.StartListening(ctx)
+	results, err := e.sst.StartListening(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to start listening: %w", err)
+		return sst.Result{}, fmt.Errorf("failed to start listening: %w", err)
 	}
 
-	fmt.Println("🔴 Recording... Press ENTER to stop")
-
-	// Channel to signal when user wants to stop
-	stopChan := make(chan bool, 1)
-	go func() {
-		fmt.Scanln()
-		logger.New().Debug("recording stop pressed. stopping sst and voice listener")
-		err := e.sst.StopListening()
-		if err != nil {
-			logger.New().WithError(err).Error("failed to stop listening")
-		}
-		stopChan <- true
-	}()
+	if handsFree {
+		fmt.Println("🎙️ Listening... just ask your question")
+	} else {
+		fmt.Println("🔴 Recording... Press ENTER to stop")
 
-	count := 0
-	// For Google SST, we need to manually process the audio chunk
-	if googleSST, ok := e.sst.(*sst.GoogleSST); ok {
 		go func() {
-			log.Debug("[voice] initial delay for sst collection")
-			time.Sleep(200 * time.Millisecond) // Longer initial delay to collect more audio
-
-			processingInterval := 2 * time.Second // Process chunks every 2 seconds instead of 100ms
-			ticker := time.NewTicker(processingInterval)
-			defer ticker.Stop()
-
-			for e.sst.IsListening() {
-				select {
-				case <-ticker.C:
-					count++
-
-					// Get debug stats before processing
-					if debugStats := googleSST.GetDebugStats(); debugStats != nil {
-						log.Debug(fmt.Sprintf("[voice] pre-processing stats [chunk:%d]: %+v", count, debugStats))
-					}
-
-					log.Debug(fmt.Sprintf("[voice] processing audio chunk [chunk:%d]", count))
-
-					// Create a new context for each audio processing request
-					chunkCtx, chunkCancel := context.WithTimeout(context.Background(), 15*time.Second)
-					err := googleSST.ProcessAudioChunk(chunkCtx)
-					chunkCancel()
-
-					if err != nil {
-						log.WithError(err).Error(fmt.Sprintf("failed to process audio chunk [chunk:%d]", count))
-					} else {
-						log.Debug(fmt.Sprintf("[voice] successfully processed chunk [chunk:%d]", count))
-					}
-
-				case <-time.After(100 * time.Millisecond):
-					// Check if we should stop listening
-					if !e.sst.IsListening() {
-						log.Debug("[voice] sst no longer listening, exiting processing loop")
-						return
-					}
-				}
+			fmt.Scanln()
+			logger.New().Debug("recording stop pressed. stopping sst and voice listener")
+			if err := e.sst.StopListening(); err != nil {
+				logger.New().WithError(err).Error("failed to stop listening")
 			}
-
-			log.Debug("[voice] sst no longer listening. processing loop exited")
 		}()
 	}
 
-	var transcripts []string
-	var prompt string
+	var finals []sst.Result
+	interim := ""
+
+	// push-to-talk starts talking straight away. Hands-free, waiting for the
+	// detective to speak doesn't count, so the clock starts with the first result.
+	var timeout <-chan time.Time
+	if !handsFree {
+		timeout = time.After(questionTimeout)
+	}
+	stopped := false
+
+	// the transcript so far, with the latest interim guess on the end
+	transcript := func() string {
+		var texts []string
+		for _, r := range finals {
+			texts = append(texts, r.Transcript)
+		}
+		return cleanTranscript(strings.Join(append(texts, interim), " "))
+	}
 
-	// Wait for transcript, user stop, or timeout
 	for {
 		select {
-		case transcript := <-transcriptChan:
-			if strings.TrimSpace(transcript) != "" {
-				transcripts = append(transcripts, strings.TrimSpace(transcript))
-				// Rebuild prompt from all transcripts in order
-				prompt = strings.Join(transcripts, " ")
-				logger.New().Debug(fmt.Sprintf("[engine] transcript received. transcripts so far: %v, combined prompt: [%s]", transcripts, prompt))
-			}
-			// Continue listening for more transcripts instead of returning immediately
-
-		case <-stopChan:
-			logger.New().Debug("[engine] user requested stop")
-
-			// Give a small delay to allow any final audio processing
-			logger.New().Debug("[engine] waiting for final audio processing...")
-			time.Sleep(500 * time.Millisecond)
-
-			// Process any final transcripts that might have come in
-			finalTranscriptTimeout := time.After(2 * time.Second)
-			for {
-				select {
-				case transcript := <-transcriptChan:
-					if strings.TrimSpace(transcript) != "" {
-						transcripts = append(transcripts, strings.TrimSpace(transcript))
-						prompt = strings.Join(transcripts, " ")
-						prompt = strings.ReplaceAll(prompt, ".", " ")
-						logger.New().Debug(fmt.Sprintf("[engine] final transcript received: '%s', combined: [%s]", transcript, prompt))
-					}
-				case <-finalTranscriptTimeout:
-					logger.New().Debug("[engine] final transcript collection timeout")
-					prompt = strings.ReplaceAll(prompt, ".", " ")
-					return strings.TrimSpace(prompt), nil
-				}
+		case result, ok := <-results:
+			if !ok {
+				// the provider closes the channel once every final result is in
+				logger.New().Debug(fmt.Sprintf("[engine] recognition finished: [%s]", transcript()))
+				fmt.Println()
+				return utterance(finals), nil
 			}
 
-		case <-ctx.Done():
-			logger.New().Debug("[engine] context timeout")
-			err = e.sst.StopListening()
-			if err != nil {
-				logger.New().WithError(err).Error("failed to stop listening on timeout")
+			if timeout == nil && !stopped {
+				timeout = time.After(questionTimeout)
 			}
 
-			if len(prompt) > 0 {
-				prompt = strings.ReplaceAll(prompt, ".", " ")
-				return strings.TrimSpace(prompt), nil
+			text := strings.TrimSpace(result.Transcript)
+			if result.IsFinal {
+				if text != "" {
+					finals = append(finals, result)
+				}
+				interim = ""
+			} else {
+				interim = text
 			}
-			return "", fmt.Errorf("voice input timed out")
+
+			// show what has been heard so far while the detective is still talking
+			fmt.Printf("\r\033[K🗣️  %s", transcript())
+
+		case <-timeout:
+			// stop rather than abandon the session, and keep reading for the
+			// final results of what has been said
+			logger.New().Debug("[engine] question timed out, stopping sst")
+			timeout = nil
+			stopped = true
+			go func() {
+				if err := e.sst.StopListening(); err != nil {
+					logger.New().WithError(err).Error("failed to stop listening on timeout")
+				}
+			}()
 		}
 	}
 }
@@ -443,13 +665,7 @@ func (e *Engine) findTtsModel(character *Character) string {
 }
 
 func (e *Engine) findNarratorTtsModel() string {
-	for _, ttsOption := range e.murder.NarratorTTS {
-		if ttsOption.Engine == e.tts.Name() {
-			return ttsOption.Model
-		}
-	}
-
-	return ""
+	return e.murder.narratorModel(e.tts.Name())
 }
 
 func (e *Engine) speakInterruptibleIntroduction(welcomeMessage, narratorModel string) {
@@ -464,9 +680,9 @@ func (e *Engine) speakInterruptibleIntroduction(welcomeMessage, narratorModel st
 		skipChan <- true
 	}()
 
-	// Start with welcome message
-	ctx, cancel := context.WithTimeout(context.Background(),
-		time.Duration(e.config.Ollama.Timeout)*time.Second)
+	// Start with welcome message. Cancelling stops playback, so no timeout
+	// here: it would cut off a long introduction mid-sentence.
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Create channels for TTS completion
@@ -474,8 +690,7 @@ func (e *Engine) speakInterruptibleIntroduction(welcomeMessage, narratorModel st
 
 	// Speak welcome message
 	go func() {
-		emotion := "Welcoming and friendly"
-		if err := e.tts.Speak(ctx, welcomeMessage, emotion, narratorModel); err != nil {
+		if err := e.tts.Speak(ctx, tts.Line{Text: welcomeMessage, Emotions: welcomeEmotion, Model: narratorModel}); err != nil {
 			if !errors.Is(err, context.Canceled) {
 				e.logger.WithError(err).Error("failed to speak welcome message")
 			}
@@ -503,9 +718,10 @@ func (e *Engine) speakInterruptibleIntroduction(welcomeMessage, narratorModel st
 
 	// Speak the introduction
 	go func() {
-		emotion := "Authorative, calm with a tone of mischief"
-		if err := e.tts.Speak(ctx, e.murder.Intro, emotion, narratorModel); err != nil {
-			e.logger.WithError(err).Error("failed to speak introduction")
+		if err := e.tts.Speak(ctx, tts.Line{Text: e.murder.Intro, Emotions: introEmotion, Model: narratorModel}); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				e.logger.WithError(err).Error("failed to speak introduction")
+			}
 		}
 		ttsDone <- true
 	}()
@@ -563,6 +779,7 @@ func (e *Engine) processAccusation(accusation string) bool {
 	}
 
 	name, weapon, location := args[0], args[1], strings.Join(args[2:], " ")
+	e.accusations = append(e.accusations, accusation)
 
 	fmt.Printf("\n🔍 Your accusation: %s killed the victim with a %s in the %s\n",
 		name, weapon, location)
@@ -573,6 +790,7 @@ func (e *Engine) processAccusation(accusation string) bool {
 		fmt.Println("🎉 Congratulations Detective! You solved the murder!")
 		fmt.Printf("The killer was indeed %s with the %s in the %s.\n",
 			e.murder.Killer, e.murder.Weapon, e.murder.Location)
+		e.revealCase()
 		return true
 	}
 
@@ -582,12 +800,50 @@ func (e *Engine) processAccusation(accusation string) bool {
 	return false
 }
 
+// revealCase tells the whole story once the case is solved
+func (e *Engine) revealCase() {
+	fmt.Printf("\n📜 The case of %s\n", e.murder.Victim)
+	fmt.Printf("Motive: %s\n", e.murder.Motive)
+
+	fmt.Println("\n🤫 What everyone was hiding:")
+	for _, char := range e.murder.Characters {
+		if len(char.Secrets) == 0 {
+			continue
+		}
+		fmt.Printf("  • %s\n", char.Name)
+		for _, secret := range char.Secrets {
+			if secret.Unlock != nil && e.isUnlocked(secret.key()) {
+				fmt.Printf("      - %s (you cracked this one)\n", secret.Text)
+			} else {
+				fmt.Printf("      - %s\n", secret.Text)
+			}
+		}
+	}
+	fmt.Println()
+}
+
 func (e *Engine) WithResponses(resp bool) *Engine {
 	e.showResponses = resp
 	return e
 }
 
 func loadMystery(filename string) (Murder, error) {
+	murder, err := readMystery(filename)
+	if err != nil {
+		return Murder{}, err
+	}
+
+	if err := murder.Validate(); err != nil {
+		return Murder{}, fmt.Errorf("invalid mystery %s: %w", filename, err)
+	}
+
+	return murder, nil
+}
+
+// readMystery decodes a mystery file without checking that it is playable.
+// Fields with the wrong type are skipped, so the best-effort murder is
+// returned alongside a decode error for the validator to inspect.
+func readMystery(filename string) (Murder, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return Murder{}, fmt.Errorf("failed to open mystery file: %w", err)
@@ -597,7 +853,7 @@ func loadMystery(filename string) (Murder, error) {
 	var murder Murder
 	decoder := json.NewDecoder(file)
 	if err := decoder.Decode(&murder); err != nil {
-		return Murder{}, fmt.Errorf("failed to decode mystery JSON: %w", err)
+		return murder, fmt.Errorf("failed to decode mystery JSON: %w", err)
 	}
 
 	return murder, nil
@@ -0,0 +1,222 @@
+package game
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"gofigure/config"
+	"gofigure/internal/logger"
+	"gofigure/internal/sst"
+	"gofigure/internal/tts"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testEngine is an engine with no devices, answering through fakeLLM and
+// saving into a temporary directory
+func testEngine(t *testing.T, m Murder) *Engine {
+	t.Helper()
+
+	return &Engine{
+		murder:   m,
+		notebook: NewNotebook(),
+		tts:      tts.NewDummyTts(),
+		sst:      sst.NewDummySST(),
+		llm:      &fakeLLM{},
+		logger:   logger.New(),
+		config: &config.Config{
+			Game: config.GameConfig{SaveDir: t.TempDir()},
+		},
+	}
+}
+
+// fixtureSST stands in for a recognizer, playing back one recorded session of
+// results each time it listens and closing the channel once they are all sent
+type fixtureSST struct {
+	sessions [][]sst.Result
+	started  int
+	stopped  int
+}
+
+func loadSessions(t *testing.T, path string) *fixtureSST {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fixtureSST{}
+	if err := json.Unmarshal(data, &f.sessions); err != nil {
+		t.Fatalf("bad fixture %s: %v", path, err)
+	}
+	return f
+}
+
+func (f *fixtureSST) StartListening(ctx context.Context) (<-chan sst.Result, error) {
+	if f.started >= len(f.sessions) {
+		return nil, sst.ErrNoMoreAudio
+	}
+	session := f.sessions[f.started]
+	f.started++
+
+	results := make(chan sst.Result)
+	go func() {
+		defer close(results)
+		for _, r := range session {
+			select {
+			case results <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return results, nil
+}
+
+func (f *fixtureSST) SetPhraseHints([]string) {}
+func (f *fixtureSST) StopListening() error    { f.stopped++; return nil }
+func (f *fixtureSST) IsListening() bool       { return false }
+func (f *fixtureSST) Provider() string        { return "fixture" }
+
+func TestGetVoiceInputFromRecordedSessions(t *testing.T) {
+	e := testEngine(t, testMurder())
+	e.sst = loadSessions(t, "testdata/voice_sessions.json")
+	e.config.Sst.AudioInput = []string{"question.wav"} // hands-free, no ENTER to press
+
+	tests := []struct {
+		transcript   string
+		confidence   float32
+		alternatives []string
+	}{
+		// the finals are joined, as unsure as the least confident of them
+		{transcript: "Where were you at midnight", confidence: 0.81},
+		{transcript: "interview Percy Lane", confidence: 0.64, alternatives: []string{"interview percy lane", "interview pussy lane"}},
+		// only an interim guess was heard before the session closed
+		{transcript: ""},
+	}
+
+	for i, tt := range tests {
+		heard, err := e.getVoiceInput()
+		if err != nil {
+			t.Fatalf("session %d: %v", i+1, err)
+		}
+		if !heard.IsFinal || heard.Transcript != tt.transcript || heard.Confidence != tt.confidence || !slices.Equal(heard.Alternatives, tt.alternatives) {
+			t.Errorf("session %d: heard %+v, want %q at %.2f with %q", i+1, heard, tt.transcript, tt.confidence, tt.alternatives)
+		}
+	}
+
+	if _, err := e.getVoiceInput(); !errors.Is(err, sst.ErrNoMoreAudio) {
+		t.Errorf("got %v once the recordings ran out, want ErrNoMoreAudio", err)
+	}
+}
+
+func TestInterviewShowsEvidenceOrAsks(t *testing.T) {
+	tests := []struct {
+		typed string
+		want  string
+	}{
+		{typed: "show the shears", want: "The detective shows you a piece of evidence: Pruning Shears - Wiped clean"},
+		{typed: "show me your hands", want: "Detective's question: show me your hands"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typed, func(t *testing.T) {
+			m := testMurder()
+			m.Evidence = []Evidence{{Name: "Pruning Shears", Description: "Wiped clean"}}
+
+			e := testEngine(t, m)
+			client := &fakeLLM{}
+			e.llm = client
+			e.scanner = bufio.NewScanner(strings.NewReader(tt.typed + "\nexit\n"))
+
+			e.startInterview(&e.murder.Characters[1])
+
+			if len(client.prompts) != 1 {
+				t.Fatalf("sent %d prompts, want 1", len(client.prompts))
+			}
+			if !strings.Contains(client.prompts[0], tt.want) {
+				t.Errorf("prompt doesn't contain %q:\n%s", tt.want, client.prompts[0])
+			}
+		})
+	}
+}
+
+// slowSST says nothing for quiet, then hears an interim guess and waits to be
+// stopped before sending the final result
+type slowSST struct {
+	quiet   time.Duration
+	stop    chan struct{}
+	stopped int
+	early   bool // stopped before anything was said
+}
+
+func (s *slowSST) StartListening(ctx context.Context) (<-chan sst.Result, error) {
+	results := make(chan sst.Result)
+	go func() {
+		defer close(results)
+		time.Sleep(s.quiet)
+		select {
+		case <-s.stop:
+			s.early = true
+		default:
+		}
+		results <- sst.Result{Transcript: "where were"}
+		select {
+		case <-s.stop:
+			results <- sst.Result{Transcript: "Where were you", IsFinal: true, Confidence: 0.9}
+		case <-ctx.Done():
+		}
+	}()
+	return results, nil
+}
+
+func (s *slowSST) SetPhraseHints([]string) {}
+func (s *slowSST) IsListening() bool       { return true }
+func (s *slowSST) Provider() string        { return "slow" }
+
+func (s *slowSST) StopListening() error {
+	s.stopped++
+	close(s.stop)
+	return nil
+}
+
+func TestGetVoiceInputStopsAtTimeout(t *testing.T) {
+	defer func(d time.Duration) { questionTimeout = d }(questionTimeout)
+	questionTimeout = 50 * time.Millisecond
+
+	e := testEngine(t, testMurder())
+	e.config.Sst.Mode = sst.ModeVAD
+
+	// waiting for the detective to speak doesn't count towards the timeout
+	slow := &slowSST{quiet: 4 * questionTimeout, stop: make(chan struct{})}
+	e.sst = slow
+
+	heard, err := e.getVoiceInput()
+	if err != nil {
+		t.Fatalf("getVoiceInput: %v", err)
+	}
+	if heard.Transcript != "Where were you" || heard.Confidence != 0.9 {
+		t.Errorf("heard %+v, want the final result sent after stopping", heard)
+	}
+	if slow.stopped != 1 || slow.early {
+		t.Errorf("stopped %d times, early %v; want once, after speech started", slow.stopped, slow.early)
+	}
+}
+
+func TestWithMicInputNeedsARecognizer(t *testing.T) {
+	e := testEngine(t, testMurder())
+	e.config.Sst.Enabled = true
+
+	if e.WithMicInput(true); e.useMicInput {
+		t.Error("listening through the dummy recognizer, which never hears anything")
+	}
+
+	e.sst = &slowSST{}
+	if e.WithMicInput(true); !e.useMicInput {
+		t.Error("voice input off with a working recognizer")
+	}
+}
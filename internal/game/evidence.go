@@ -0,0 +1,235 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Evidence is a physical clue the detective can find and examine. Evidence
+// with neither a location nor a trigger is known from the start, like the body.
+type Evidence struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// Location is where the evidence is found by examining the place
+	Location string `json:"location,omitempty"`
+	// RevealedBy turns the evidence up during an interview
+	RevealedBy *EvidenceTrigger `json:"revealed_by,omitempty"`
+}
+
+// EvidenceTrigger reveals evidence when a character lets slip one of its
+// keywords. Asking about them isn't enough, unless the trigger says so.
+type EvidenceTrigger struct {
+	Character string   `json:"character,omitempty"` // only while interviewing this character
+	Keywords  []string `json:"keywords"`            // any of these in the answer
+	Asked     bool     `json:"asked,omitempty"`     // also match the detective's question
+}
+
+// public reports whether the evidence is known before the investigation starts
+func (ev *Evidence) public() bool {
+	return ev.Location == "" && ev.RevealedBy == nil
+}
+
+// triggeredBy reports whether an exchange with a character reveals the evidence
+func (t *EvidenceTrigger) triggeredBy(m *Murder, char *Character, question, answer string) bool {
+	// names may be shortened, as elsewhere in a mystery file
+	if t.Character != "" {
+		if who := m.findCharacterByName(t.Character); who == nil || who.Name != char.Name {
+			return false
+		}
+	}
+
+	text := answer
+	if t.Asked {
+		text = question + " " + answer
+	}
+	for _, keyword := range t.Keywords {
+		if mentionsPhrase(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// places returns the crime scene and everywhere evidence can be found
+func (m Murder) places() []string {
+	places := []string{m.Location}
+	for _, ev := range m.Evidence {
+		if ev.Location != "" && !containsFold(places, ev.Location) {
+			places = append(places, ev.Location)
+		}
+	}
+	return places
+}
+
+// matchesName reports whether what the detective typed refers to name. A
+// loose match works in either direction on whole words, so "freezer" finds
+// "Ship's Cold Storage Freezer" but "a" or "ice" don't.
+func matchesName(name, typed string, exact bool) bool {
+	nameWords := splitName(parenthetical.ReplaceAllString(name, ""))
+	typedWords := splitName(typed)
+	if len(typedWords) > 0 && slices.Contains([]string{"the", "a", "an"}, typedWords[0]) {
+		typedWords = typedWords[1:]
+	}
+
+	if len(typedWords) == 0 || len(nameWords) == 0 {
+		return false
+	}
+	if exact {
+		return slices.Equal(nameWords, typedWords)
+	}
+	return containsWords(nameWords, typedWords) || containsWords(typedWords, nameWords)
+}
+
+// splitName splits a name into lower case words, so "Marcus's journal" is
+// marcus and journal
+func splitName(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’'
+	})
+	for i, w := range words {
+		w = strings.TrimSuffix(strings.TrimSuffix(w, "'s"), "’s")
+		words[i] = strings.Trim(w, "'’")
+	}
+	return slices.DeleteFunc(words, func(w string) bool { return w == "" })
+}
+
+// mentionsPhrase reports whether text says phrase in whole words, so "cabin"
+// isn't found in "cabinet"
+func mentionsPhrase(text, phrase string) bool {
+	words := splitName(phrase)
+	return len(words) > 0 && containsWords(splitName(text), words)
+}
+
+// containsWords reports whether words appear together, in order, in text
+func containsWords(text, words []string) bool {
+	for i := 0; i+len(words) <= len(text); i++ {
+		if slices.Equal(text[i:i+len(words)], words) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFound reports whether the detective has come across the evidence
+func (e *Engine) hasFound(ev *Evidence) bool {
+	return ev.public() || slices.Contains(e.evidence, ev.Name)
+}
+
+// reveal adds evidence to what the detective has found, announcing it and
+// filing it in the notebook. It reports whether the evidence was new.
+func (e *Engine) reveal(ev *Evidence) bool {
+	if e.hasFound(ev) {
+		return false
+	}
+
+	e.evidence = append(e.evidence, ev.Name)
+	e.notebook.AddEvidence(ev)
+	fmt.Printf("🔎 New evidence: %s (use 'examine %s' to take a closer look)\n", ev.Name, strings.ToLower(ev.Name))
+	return true
+}
+
+// revealFromInterview checks an exchange for anything it brings to light
+func (e *Engine) revealFromInterview(char *Character, question, answer string) {
+	for i := range e.murder.Evidence {
+		ev := &e.murder.Evidence[i]
+		if ev.RevealedBy != nil && ev.RevealedBy.triggeredBy(&e.murder, char, question, answer) {
+			e.reveal(ev)
+		}
+	}
+}
+
+// examine looks closely at a piece of evidence, or searches a place for it.
+// Exact names win, and a loose match prefers a place, so "freezer" searches
+// the freezer rather than showing the freezer thermostat.
+func (e *Engine) examine(target string) {
+	if ev := e.findFoundEvidence(target, true); ev != nil {
+		e.showEvidence(ev)
+		return
+	}
+	for _, exact := range []bool{true, false} {
+		if place := e.murder.findPlace(target, exact); place != "" {
+			if !e.canSearch(place) {
+				fmt.Printf("The %s is elsewhere. Go there first (type 'map' to find your way).\n", place)
+				return
+			}
+			e.search(place)
+			return
+		}
+	}
+	if ev := e.findFoundEvidence(target, false); ev != nil {
+		e.showEvidence(ev)
+		return
+	}
+
+	fmt.Printf("You haven't found anything called '%s'. Type 'evidence' to see what you have.\n", target)
+}
+
+func (e *Engine) findFoundEvidence(name string, exact bool) *Evidence {
+	for i := range e.murder.Evidence {
+		if ev := &e.murder.Evidence[i]; e.hasFound(ev) && matchesName(ev.Name, name, exact) {
+			return ev
+		}
+	}
+	return nil
+}
+
+func (m Murder) findPlace(name string, exact bool) string {
+	for _, place := range m.places() {
+		if matchesName(place, name, exact) {
+			return place
+		}
+	}
+	return ""
+}
+
+func (e *Engine) showEvidence(ev *Evidence) {
+	fmt.Printf("\n🔍 %s\n%s\n", ev.Name, ev.Description)
+	if ev.Location != "" {
+		fmt.Printf("Found in: %s\n", ev.Location)
+	}
+	fmt.Println()
+}
+
+// search turns up any evidence at a place the detective hasn't found yet
+func (e *Engine) search(place string) {
+	fmt.Printf("You search the %s...\n", place)
+
+	found := false
+	for i := range e.murder.Evidence {
+		ev := &e.murder.Evidence[i]
+		if strings.EqualFold(ev.Location, place) && e.reveal(ev) {
+			found = true
+		}
+	}
+	if !found {
+		fmt.Println("Nothing new turns up.")
+	}
+}
+
+// listEvidence shows everything found so far, in the order the mystery lists it
+func (e *Engine) listEvidence() {
+	var found []*Evidence
+	for i := range e.murder.Evidence {
+		if ev := &e.murder.Evidence[i]; e.hasFound(ev) {
+			found = append(found, ev)
+		}
+	}
+
+	if len(found) == 0 {
+		fmt.Println("You haven't found any evidence yet. Try examining the crime scene.")
+		return
+	}
+
+	fmt.Println("\n🧾 Evidence:")
+	for _, ev := range found {
+		if ev.Location != "" {
+			fmt.Printf("  • %s (%s)\n", ev.Name, ev.Location)
+		} else {
+			fmt.Printf("  • %s\n", ev.Name)
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTriggeredBy(t *testing.T) {
+	m := testMurder()
+	m.Characters = append(m.Characters, Character{Name: "Sally Moss"}, Character{Name: "Al Brooks"})
+
+	trigger := &EvidenceTrigger{Character: "Percy", Keywords: []string{"glass", " ", "Broken Pane"}}
+	asked := &EvidenceTrigger{Keywords: []string{"glass"}, Asked: true}
+	cabin := &EvidenceTrigger{Character: "Al", Keywords: []string{"cabin", "Marcus"}}
+
+	tests := []struct {
+		name      string
+		trigger   *EvidenceTrigger
+		character string
+		question  string
+		answer    string
+		want      bool
+	}{
+		{name: "answer mentions a keyword", trigger: trigger, character: "Percy Lane", question: "What did you hear?", answer: "Glass breaking, at midnight.", want: true},
+		{name: "keywords ignore case", trigger: trigger, character: "Percy Lane", question: "And then?", answer: "I saw the broken pane.", want: true},
+		{name: "asking isn't enough", trigger: trigger, character: "Percy Lane", question: "Did you hear glass break?", answer: "I heard nothing.", want: false},
+		{name: "blank keywords never match", trigger: trigger, character: "Percy Lane", question: "Well?", answer: "I'd rather not say.", want: false},
+		{name: "someone else", trigger: trigger, character: "Vera Holt", question: "What did you hear?", answer: "Glass breaking.", want: false},
+		{name: "anyone, when no character is set", trigger: asked, character: "Vera Holt", question: "What did you hear?", answer: "Glass breaking.", want: true},
+		{name: "question counts when asked is set", trigger: asked, character: "Vera Holt", question: "Did you hear glass break?", answer: "No.", want: true},
+		{name: "whole words only", trigger: cabin, character: "Al Brooks", question: "Where are the pills?", answer: "In the locked drug cabinet.", want: false},
+		{name: "possessives", trigger: cabin, character: "Al Brooks", question: "Whose is this?", answer: "Marcus's, I think.", want: true},
+		{name: "short names are whole words", trigger: cabin, character: "Sally Moss", question: "Where were you?", answer: "In my cabin.", want: false},
+		{name: "short names still match", trigger: cabin, character: "Al Brooks", question: "Where were you?", answer: "In my cabin.", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			char := m.findCharacterByName(tt.character)
+			if got := tt.trigger.triggeredBy(&m, char, tt.question, tt.answer); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesName(t *testing.T) {
+	tests := []struct {
+		name  string
+		typed string
+		exact bool
+		want  bool
+	}{
+		{name: "Pruning Shears", typed: "pruning shears", exact: true, want: true},
+		{name: "Pruning Shears", typed: "  the Pruning  Shears ", exact: true, want: true},
+		{name: "Pruning Shears", typed: "shears", exact: true, want: false},
+		{name: "Pruning Shears", typed: "shears", want: true},
+		{name: "Ship's Cold Storage Freezer", typed: "freezer", want: true},
+		{name: "Ship's Cold Storage Freezer", typed: "cold storage", want: true},
+		{name: "Ship's Cold Storage Freezer", typed: "storage cold", want: false},
+		{name: "Freezer", typed: "the big freezer", want: true},
+		{name: "Blood-stained Glove", typed: "glove", want: true},
+		{name: "Garden Shed (locked)", typed: "garden shed", exact: true, want: true},
+		{name: "Garden Shed", typed: "a", want: false},
+		{name: "Garden Shed", typed: "the", want: false},
+		{name: "Garden Shed", typed: "den", want: false},
+		{name: "Garden Shed", typed: "", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := matchesName(tt.name, tt.typed, tt.exact); got != tt.want {
+			t.Errorf("matchesName(%q, %q, %v) = %v, want %v", tt.name, tt.typed, tt.exact, got, tt.want)
+		}
+	}
+}
+
+func TestExamine(t *testing.T) {
+	m := testMurder()
+	m.Evidence = []Evidence{
+		{Name: "Pruning Shears", Description: "Wiped clean", Location: "Greenhouse"},
+		{Name: "Muddy boots", Description: "Size nine", Location: "Garden Shed"},
+		{Name: "Torn letter", Description: "Half a page", RevealedBy: &EvidenceTrigger{Keywords: []string{"letter"}}},
+	}
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{target: "greenhouse", want: []string{"Pruning Shears"}},
+		{target: "the garden shed", want: []string{"Muddy boots"}},
+		{target: "shed", want: []string{"Muddy boots"}},
+		{target: "a", want: nil},
+		{target: "den", want: nil},
+		{target: "torn letter", want: nil}, // not found yet, and not a place
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			e := testEngine(t, m)
+			e.examine(tt.target)
+			if !slices.Equal(e.evidence, tt.want) {
+				t.Errorf("found %v, want %v", e.evidence, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,310 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gofigure/internal/llm"
+	"gofigure/internal/logger"
+	"math/rand"
+	"strings"
+)
+
+// GeneratorOptions shape a procedurally generated mystery
+type GeneratorOptions struct {
+	Setting    string
+	Suspects   int
+	Difficulty string // easy, medium or hard
+	Seed       int64
+}
+
+// Generator drives an llm through a multi-step pipeline to write a new mystery.
+// Every choice the generator makes itself (who the killer is, who lies, which
+// voices are cast) comes from the seed, so the same seed against a
+// deterministic llm always produces the same case.
+type Generator struct {
+	llm    llm.LLM
+	opts   GeneratorOptions
+	rand   *rand.Rand
+	logger *logger.Log
+}
+
+type difficultyProfile struct {
+	redHerrings int
+	unreliable  int
+	clueStyle   string
+}
+
+var difficulties = map[string]difficultyProfile{
+	"easy":   {redHerrings: 1, unreliable: 1, clueStyle: "Clues are clear and point fairly directly at the truth."},
+	"medium": {redHerrings: 2, unreliable: 2, clueStyle: "Clues are indirect; the truth emerges by comparing testimony."},
+	"hard":   {redHerrings: 4, unreliable: 3, clueStyle: "Clues are subtle and scattered; several suspects have convincing motives and lies of their own."},
+}
+
+// generatorVoices are the google voices handed out to generated characters
+var generatorVoices = []string{
+	"en-GB-Standard-D",
+	"en-GB-Wavenet-N",
+	"en-US-Wavenet-H",
+	"en-AU-Wavenet-B",
+	"en-GB-Chirp3-HD-Iapetus",
+	"en-GB-Chirp3-HD-Enceladus",
+	"en-US-Chirp3-HD-Sulafat",
+	"en-IN-Chirp3-HD-Zubenelgenubi",
+}
+
+const generatorNarratorVoice = "en-GB-Chirp3-HD-Charon"
+
+func NewGenerator(llmClient llm.LLM, opts GeneratorOptions) (*Generator, error) {
+	if strings.TrimSpace(opts.Setting) == "" {
+		return nil, fmt.Errorf("a setting is required")
+	}
+	if opts.Suspects < 2 {
+		return nil, fmt.Errorf("at least 2 suspects are required, got %d", opts.Suspects)
+	}
+	if opts.Difficulty == "" {
+		opts.Difficulty = "medium"
+	}
+	if _, ok := difficulties[opts.Difficulty]; !ok {
+		return nil, fmt.Errorf("unknown difficulty '%s' (easy, medium or hard)", opts.Difficulty)
+	}
+
+	return &Generator{
+		llm:    llmClient,
+		opts:   opts,
+		rand:   rand.New(rand.NewSource(opts.Seed)),
+		logger: logger.New(),
+	}, nil
+}
+
+// Generate writes a complete mystery and checks it the same way the validator does
+func (g *Generator) Generate(ctx context.Context) (*Murder, error) {
+	profile := difficulties[g.opts.Difficulty]
+	m := &Murder{}
+
+	// 1. premise
+	var premise struct {
+		Title   string `json:"title"`
+		Premise string `json:"premise"`
+	}
+	if err := g.ask(ctx, "premise", fmt.Sprintf(`Invent the premise for a murder mystery game.
+Setting: %s
+%s
+Reply only in this JSON structure {"title": string, "premise": string}`,
+		g.opts.Setting, profile.clueStyle), &premise); err != nil {
+		return nil, err
+	}
+	m.Title = premise.Title
+
+	// 2. victim
+	var victim struct {
+		Victim   string `json:"victim"`
+		Location string `json:"location"`
+	}
+	if err := g.ask(ctx, "victim", fmt.Sprintf(`%s
+Decide who was murdered and the room or place their body was found.
+Reply only in this JSON structure {"victim": string, "location": string}`,
+		g.caseFile(premise.Premise, m)), &victim); err != nil {
+		return nil, err
+	}
+	m.Victim, m.Location = victim.Victim, victim.Location
+
+	// 3. suspects
+	var cast struct {
+		Characters []struct {
+			Name        string `json:"name"`
+			Personality string `json:"personality"`
+		} `json:"characters"`
+	}
+	if err := g.ask(ctx, "suspects", fmt.Sprintf(`%s
+Invent exactly %d suspects who were present. Give each a distinctive full name and a short personality description.
+Reply only in this JSON structure {"characters": [{"name": string, "personality": string}]}`,
+		g.caseFile(premise.Premise, m), g.opts.Suspects), &cast); err != nil {
+		return nil, err
+	}
+	if len(cast.Characters) < g.opts.Suspects {
+		return nil, fmt.Errorf("llm invented %d suspects, wanted %d", len(cast.Characters), g.opts.Suspects)
+	}
+	for _, c := range cast.Characters[:g.opts.Suspects] {
+		m.Characters = append(m.Characters, Character{Name: c.Name, Personality: c.Personality, Reliable: true})
+	}
+
+	// the generator, not the llm, decides who did it and who lies
+	killer := g.rand.Intn(len(m.Characters))
+	m.Killer = m.Characters[killer].Name
+	m.Characters[killer].Reliable = false
+
+	liars := g.rand.Perm(len(m.Characters))
+	for _, i := range liars[:min(profile.unreliable, len(liars))] {
+		m.Characters[i].Reliable = false
+	}
+
+	// 4. killer, means and opportunity
+	var crime struct {
+		Weapon      string `json:"weapon"`
+		Motive      string `json:"motive"`
+		Opportunity string `json:"opportunity"`
+	}
+	if err := g.ask(ctx, "means", fmt.Sprintf(`%s
+The killer is %s. Decide the murder weapon, the killer's motive and how they had the opportunity.
+Reply only in this JSON structure {"weapon": string, "motive": string, "opportunity": string}`,
+		g.caseFile(premise.Premise, m), m.Killer), &crime); err != nil {
+		return nil, err
+	}
+	m.Weapon, m.Motive = crime.Weapon, crime.Motive
+
+	// 5. per-character knowledge and secrets
+	for i := range m.Characters {
+		char := &m.Characters[i]
+
+		role := "You are innocent of the murder. Give them first-hand observations that help the detective, and a personal secret unrelated to the murder."
+		if i == killer {
+			role = fmt.Sprintf("This character is the killer. Their opportunity: %s. Give them a cover story, facts that only the killer would know, and secrets that would expose them.", crime.Opportunity)
+		}
+
+		var profileReply struct {
+			Knowledge []string `json:"knowledge"`
+			Secrets   []string `json:"secrets"`
+		}
+		if err := g.ask(ctx, "knowledge:"+char.Name, fmt.Sprintf(`%s
+Write what %s (%s) knows about the evening.
+%s
+%s
+Reply only in this JSON structure {"knowledge": [string], "secrets": [string]}`,
+			g.caseFile(premise.Premise, m), char.Name, char.Personality, role, profile.clueStyle), &profileReply); err != nil {
+			return nil, err
+		}
+		char.Knowledge = profileReply.Knowledge
+		for _, secret := range profileReply.Secrets {
+			char.Secrets = append(char.Secrets, Secret{Text: secret})
+		}
+	}
+
+	// 6. red herrings pin suspicious but innocent details on other suspects
+	innocents := []string{}
+	for i, c := range m.Characters {
+		if i != killer {
+			innocents = append(innocents, c.Name)
+		}
+	}
+	var herrings struct {
+		RedHerrings []struct {
+			Character string `json:"character"`
+			Knowledge string `json:"knowledge"`
+			Secret    string `json:"secret"`
+		} `json:"red_herrings"`
+	}
+	if err := g.ask(ctx, "red herrings", fmt.Sprintf(`%s
+Invent %d red herrings: suspicious but innocent details about these suspects: %s.
+Each red herring adds something the character knows and a secret that makes them look guilty.
+Reply only in this JSON structure {"red_herrings": [{"character": string, "knowledge": string, "secret": string}]}`,
+		g.caseFile(premise.Premise, m), profile.redHerrings, strings.Join(innocents, ", ")), &herrings); err != nil {
+		return nil, err
+	}
+	for _, h := range herrings.RedHerrings {
+		char := m.findCharacterByName(h.Character)
+		if char == nil || char.Name == m.Killer {
+			g.logger.Debug(fmt.Sprintf("[generate] skipping red herring for unknown suspect '%s'", h.Character))
+			continue
+		}
+		if h.Knowledge != "" {
+			char.Knowledge = append(char.Knowledge, h.Knowledge)
+		}
+		if h.Secret != "" {
+			char.Secrets = append(char.Secrets, Secret{Text: h.Secret})
+		}
+	}
+
+	// 7. introduction, without giving the game away
+	var intro struct {
+		Introduction string `json:"introduction"`
+	}
+	if err := g.ask(ctx, "introduction", fmt.Sprintf(`%s
+Write the narrator's introduction read to the detective: set the scene, describe the discovery of %s in %s and why the detective must solve it now.
+Never reveal the killer, the motive or the weapon.
+Reply only in this JSON structure {"introduction": string}`,
+		g.caseFile(premise.Premise, m), m.Victim, m.Location), &intro); err != nil {
+		return nil, err
+	}
+	m.Intro = intro.Introduction
+
+	g.castVoices(m)
+
+	if err := checkGenerated(m); err != nil {
+		return nil, fmt.Errorf("generated mystery is invalid: %w", err)
+	}
+	return m, nil
+}
+
+// checkGenerated runs the checks of gofigure validate over the mystery as it
+// will be written, so a generated file never fails them
+func checkGenerated(m *Murder) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, p := range validateMystery(data) {
+		if p.Severity == SeverityError {
+			errs = append(errs, fmt.Errorf("%s: %s", p.Path, p.Message))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// caseFile summarises what has been decided so far so each step stays consistent
+func (g *Generator) caseFile(premise string, m *Murder) string {
+	var b strings.Builder
+	b.WriteString("You are writing a murder mystery game.\n\nCASE FILE SO FAR:\n")
+	b.WriteString(fmt.Sprintf("- Setting: %s\n", g.opts.Setting))
+	if m.Title != "" {
+		b.WriteString(fmt.Sprintf("- Title: %s\n", m.Title))
+	}
+	if premise != "" {
+		b.WriteString(fmt.Sprintf("- Premise: %s\n", premise))
+	}
+	if m.Victim != "" {
+		b.WriteString(fmt.Sprintf("- Victim: %s, found in %s\n", m.Victim, m.Location))
+	}
+	if m.Weapon != "" {
+		b.WriteString(fmt.Sprintf("- Weapon: %s\n- Motive: %s\n", m.Weapon, m.Motive))
+	}
+	for _, c := range m.Characters {
+		b.WriteString(fmt.Sprintf("- Suspect: %s (%s)\n", c.Name, c.Personality))
+	}
+	return b.String()
+}
+
+func (g *Generator) castVoices(m *Murder) {
+	m.NarratorTTS = []TTS{{Engine: "google", Model: generatorNarratorVoice}}
+
+	voices := g.rand.Perm(len(generatorVoices))
+	for i := range m.Characters {
+		m.Characters[i].TTS = []TTS{{Engine: "google", Model: generatorVoices[voices[i%len(voices)]]}}
+	}
+}
+
+// ask runs one pipeline step and decodes its JSON reply, re-asking once if it does not parse
+func (g *Generator) ask(ctx context.Context, step, prompt string, v any) error {
+	g.logger.Info(fmt.Sprintf("✍️  Writing %s...", step))
+
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		resp, err := g.llm.GenerateResponse(ctx, prompt)
+		if err != nil {
+			return fmt.Errorf("generating %s: %w", step, err)
+		}
+
+		obj, err := llm.ExtractJSON(resp)
+		if err == nil {
+			err = json.Unmarshal([]byte(obj), v)
+		}
+		if lastErr = err; lastErr == nil {
+			return nil
+		}
+		g.logger.Debug(fmt.Sprintf("[generate] unparseable %s reply: %s", step, resp))
+	}
+	return fmt.Errorf("generating %s: %w", step, lastErr)
+}
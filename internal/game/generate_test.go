@@ -0,0 +1,109 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var suspectCount = regexp.MustCompile(`exactly (\d+) suspects`)
+
+// writer answers each step of the generator pipeline the same way every time
+func writer(prompt string) string {
+	switch {
+	case strings.Contains(prompt, `{"title": string, "premise": string}`):
+		return `{"title": "Death at the Lighthouse", "premise": "A storm strands the keeper's guests overnight."}`
+	case strings.Contains(prompt, `{"victim": string, "location": string}`):
+		return `{"victim": "Keeper Finch", "location": "Lamp Room"}`
+	case strings.Contains(prompt, `{"characters": [`):
+		n, _ := strconv.Atoi(suspectCount.FindStringSubmatch(prompt)[1])
+		var cast []string
+		for i := 0; i < n; i++ {
+			cast = append(cast, fmt.Sprintf(`{"name": "Suspect %c", "personality": "Nervous"}`, 'A'+i))
+		}
+		return `{"characters": [` + strings.Join(cast, ", ") + `]}`
+	case strings.Contains(prompt, `{"weapon": string`):
+		return `{"weapon": "Brass Telescope", "motive": "An inheritance", "opportunity": "Alone with the keeper at midnight"}`
+	case strings.Contains(prompt, `{"knowledge": [string]`):
+		return "```json\n" + `{"knowledge": ["Saw the brass telescope by the lamp"], "secrets": ["Owes the keeper money"]}` + "\n```"
+	case strings.Contains(prompt, `{"red_herrings": [`):
+		return `{"red_herrings": [{"character": "Suspect B", "knowledge": "Argued with the keeper at dinner", "secret": "Hid a letter"}]}`
+	case strings.Contains(prompt, `{"introduction": string}`):
+		return `{"introduction": "The keeper lies dead at the top of the lighthouse."}`
+	}
+	return "I don't know what to write"
+}
+
+func generate(t *testing.T, opts GeneratorOptions, answer func(string) string) ([]byte, error) {
+	t.Helper()
+
+	g, err := NewGenerator(&fakeLLM{answer: answer}, opts)
+	if err != nil {
+		t.Fatalf("NewGenerator: %v", err)
+	}
+	m, err := g.Generate(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(m, "", "  ")
+}
+
+func TestGenerateSameSeedSameMystery(t *testing.T) {
+	opts := GeneratorOptions{Setting: "a lighthouse in a storm", Suspects: 4, Difficulty: "hard", Seed: 42}
+
+	first, err := generate(t, opts, writer)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	second, err := generate(t, opts, writer)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("seed %d wrote two different mysteries:\n%s\n\n%s", opts.Seed, first, second)
+	}
+
+	for _, p := range validateMystery(first) {
+		if p.Severity == SeverityError {
+			t.Errorf("generated mystery fails validation: %s: %s", p.Path, p.Message)
+		}
+	}
+}
+
+func TestGenerateSeedChoosesTheCase(t *testing.T) {
+	killers := map[string]bool{}
+	for seed := int64(0); seed < 10; seed++ {
+		data, err := generate(t, GeneratorOptions{Setting: "a lighthouse", Suspects: 4, Seed: seed}, writer)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		var m Murder
+		if err := json.Unmarshal(data, &m); err != nil {
+			t.Fatal(err)
+		}
+		killers[m.Killer] = true
+	}
+
+	if len(killers) < 2 {
+		t.Errorf("10 seeds all chose the same killer: %v", killers)
+	}
+}
+
+func TestGenerateRejectsInvalidMysteries(t *testing.T) {
+	noIntro := func(prompt string) string {
+		if strings.Contains(prompt, `{"introduction": string}`) {
+			return `{"introduction": "  "}`
+		}
+		return writer(prompt)
+	}
+
+	_, err := generate(t, GeneratorOptions{Setting: "a lighthouse", Suspects: 2, Seed: 1}, noIntro)
+	if err == nil || !strings.Contains(err.Error(), "introduction") {
+		t.Errorf("got %v, want the blank introduction rejected", err)
+	}
+}
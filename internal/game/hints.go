@@ -0,0 +1,102 @@
+package game
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// asides such as "(Celebrity Chef)" are never said aloud
+var parenthetical = regexp.MustCompile(`\s*\([^)]*\)`)
+
+// commandWords are spoken in voice mode, so the recognizer should expect them
+var commandWords = []string{
+	"list", "interview", "accuse", "examine", "evidence", "note", "notes", "search", "show",
+	"go", "look", "map", "save", "load", "help", "text", "voice", "exit", "quit",
+}
+
+// phraseHints lists what a recognizer is most likely to mishear: everyone's
+// names, the weapon, the location and the proper nouns of the story
+func (m Murder) phraseHints() []string {
+	var hints []string
+	seen := make(map[string]bool)
+
+	add := func(phrase string) {
+		phrase = strings.TrimSpace(parenthetical.ReplaceAllString(phrase, ""))
+		key := strings.ToLower(phrase)
+		if phrase == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		hints = append(hints, phrase)
+	}
+
+	for _, char := range m.Characters {
+		add(char.Name)
+		// players usually say just one part of a name, e.g. "interview hawthorne"
+		for _, word := range strings.Fields(parenthetical.ReplaceAllString(char.Name, "")) {
+			if word = strings.Trim(word, ".,'\""); len(word) > 2 && unicode.IsUpper([]rune(word)[0]) && !abbreviations[strings.ToLower(word)] {
+				add(word)
+			}
+		}
+	}
+
+	add(m.Victim)
+	add(m.Weapon)
+	add(m.Location)
+	add(m.Title)
+	for _, ev := range m.Evidence {
+		add(ev.Name)
+		add(ev.Location)
+	}
+	for _, loc := range m.Locations {
+		add(loc.Name)
+	}
+
+	text := []string{m.Intro}
+	for _, char := range m.Characters {
+		text = append(text, char.Knowledge...)
+	}
+	for _, t := range text {
+		for _, noun := range properNouns(t) {
+			add(noun)
+		}
+	}
+
+	return hints
+}
+
+// properNouns returns runs of capitalised words that don't start a sentence,
+// e.g. "Aurora Star" in "aboard the Aurora Star." Titles such as "Dr." don't
+// end a sentence, and possessives are reduced to the name.
+func properNouns(text string) []string {
+	var nouns, run []string
+	flush := func() {
+		if len(run) > 0 {
+			nouns = append(nouns, strings.Join(run, " "))
+			run = nil
+		}
+	}
+
+	sentenceStart := true
+	for _, field := range strings.Fields(text) {
+		word := strings.Trim(field, `.,;:!?"()`)
+		word = strings.TrimSuffix(strings.TrimSuffix(word, "'s"), "’s")
+
+		title := abbreviations[strings.ToLower(word)]
+		if !sentenceStart && !title && len(word) > 2 && unicode.IsUpper([]rune(word)[0]) {
+			run = append(run, word)
+		} else {
+			flush()
+		}
+
+		// punctuation ends a run of names as well as a sentence
+		if last := field[len(field)-1]; strings.ContainsRune(`.,;:!?"()`, rune(last)) {
+			flush()
+		}
+		sentenceStart = strings.ContainsAny(field[len(field)-1:], ".!?") && !title
+	}
+	flush()
+
+	return nouns
+}
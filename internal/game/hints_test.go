@@ -0,0 +1,69 @@
+package game
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProperNouns(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"We sailed aboard the Aurora Star.", []string{"Aurora Star"}},
+		{"The captain left early.", nil},
+		{"Nobody saw it. Then Vera left.", []string{"Vera"}},
+		{"Where was Percy? Nobody knows! Honestly.", []string{"Percy"}},
+		{"I borrowed Vera's shears.", []string{"Vera"}},
+		{"I read the Captain’s log.", []string{"Captain"}},
+		{"Tom's hat was found.", nil},
+		{"Ask Dr. Finch about it.", []string{"Finch"}},
+		{"I made cocoa for Mrs. Oduya's guests.", []string{"Oduya"}},
+		{"I saw Tom, Vera and Percy Lane.", []string{"Tom", "Vera", "Percy Lane"}},
+		{"I met Al at the bar.", nil},
+		{"The Greenhouse (by the Lake) was locked.", []string{"Greenhouse", "Lake"}},
+		{"", nil},
+	}
+
+	for _, tt := range tests {
+		if got := properNouns(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("properNouns(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestPhraseHints(t *testing.T) {
+	m := testMurder()
+	m.Characters = append(m.Characters, Character{Name: "Chef Marco Bellini (Celebrity Chef)", Knowledge: []string{"Cooked for Vera Holt's party aboard the Silver Swan."}})
+	m.Evidence = []Evidence{{Name: "Muddy Boots", Location: "Potting Shed"}}
+	m.Locations = []Location{{Name: "Greenhouse"}, {Name: "Parlour"}}
+	m.Intro = "Old Tom was found by Mrs. Oduya. The storm raged over Blackwood Manor."
+
+	hints := m.phraseHints()
+
+	for _, want := range []string{
+		"Vera Holt", "Vera", "Holt", // names, and the parts of them players say
+		"Mrs. Oduya", "Oduya",
+		"Chef Marco Bellini", "Marco", "Bellini", // without the aside
+		"Old Tom", "Pruning Shears", "Greenhouse", "The Greenhouse Affair",
+		"Muddy Boots", "Potting Shed", "Parlour",
+		"Blackwood Manor", "Silver Swan", // proper nouns of the story
+	} {
+		if !slices.Contains(hints, want) {
+			t.Errorf("missing hint %q in %q", want, hints)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, hint := range hints {
+		if strings.Contains(hint, "(") || hint == "Mrs" {
+			t.Errorf("unhelpful hint %q", hint)
+		}
+		if key := strings.ToLower(hint); seen[key] {
+			t.Errorf("hint %q given twice", hint)
+		} else {
+			seen[key] = true
+		}
+	}
+}
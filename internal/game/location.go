@@ -0,0 +1,241 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Location is a room the detective can walk around. Evidence is placed in a
+// room by its own location field.
+type Location struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// Exits are the rooms reachable from here. Passages work both ways, so
+	// each only needs listing on one side.
+	Exits      []string `json:"exits,omitempty"`
+	Characters []string `json:"characters,omitempty"` // who can be found here
+	Ambience   string   `json:"ambience,omitempty"`   // what the detective hears on walking in
+}
+
+// findLocation matches a room the way examine matches places
+func (m Murder) findLocation(name string, exact bool) *Location {
+	for i := range m.Locations {
+		if matchesName(m.Locations[i].Name, name, exact) {
+			return &m.Locations[i]
+		}
+	}
+	return nil
+}
+
+// exits lists the rooms next to room, whichever side declared the passage
+func (m Murder) exits(room string) []string {
+	var exits []string
+	for _, loc := range m.Locations {
+		switch {
+		case strings.EqualFold(loc.Name, room):
+			for _, exit := range loc.Exits {
+				if !containsFold(exits, exit) {
+					exits = append(exits, exit)
+				}
+			}
+		case containsFold(loc.Exits, room) && !containsFold(exits, loc.Name):
+			exits = append(exits, loc.Name)
+		}
+	}
+	return exits
+}
+
+// route is the shortest walk from one room to another, excluding the room
+// the walk starts in, or nil if there is no way through
+func (m Murder) route(from, to string) []string {
+	previous := map[string]string{strings.ToLower(from): ""}
+	queue := []string{from}
+
+	for len(queue) > 0 {
+		room := queue[0]
+		queue = queue[1:]
+
+		if strings.EqualFold(room, to) {
+			var path []string
+			for r := room; !strings.EqualFold(r, from); r = previous[strings.ToLower(r)] {
+				path = append([]string{r}, path...)
+			}
+			return path
+		}
+
+		for _, next := range m.exits(room) {
+			if _, seen := previous[strings.ToLower(next)]; !seen {
+				previous[strings.ToLower(next)] = room
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// locationOf is the room a character can be found in, or "" if the mystery
+// doesn't place them, in which case they can be interviewed from anywhere
+func (m Murder) locationOf(char *Character) string {
+	for _, loc := range m.Locations {
+		for _, name := range loc.Characters {
+			if c := m.findCharacterByName(name); c != nil && strings.EqualFold(c.Name, char.Name) {
+				return loc.Name
+			}
+		}
+	}
+	return ""
+}
+
+// currentLocation is where the detective is, or nil in a mystery without rooms
+func (e *Engine) currentLocation() *Location {
+	if len(e.murder.Locations) == 0 {
+		return nil
+	}
+	if loc := e.murder.findLocation(e.room, true); loc != nil {
+		return loc
+	}
+	// the detective starts in the first room listed
+	return &e.murder.Locations[0]
+}
+
+// isPresent reports whether the detective can talk to a character right now
+func (e *Engine) isPresent(char *Character) bool {
+	room := e.murder.locationOf(char)
+	return room == "" || strings.EqualFold(room, e.currentLocation().Name)
+}
+
+// charactersIn lists everyone the mystery places in a room
+func (e *Engine) charactersIn(room string) []string {
+	var names []string
+	for _, char := range e.murder.Characters {
+		if strings.EqualFold(e.murder.locationOf(&char), room) {
+			names = append(names, char.Name)
+		}
+	}
+	return names
+}
+
+// goTo walks the detective to a room, through any rooms in between
+func (e *Engine) goTo(target string) {
+	here := e.currentLocation()
+	if here == nil {
+		fmt.Println("This mystery has no map. Everyone is within reach, so just interview them.")
+		return
+	}
+
+	target = strings.TrimPrefix(strings.TrimSpace(target), "to ")
+	dest := e.murder.findLocation(target, true)
+	if dest == nil {
+		dest = e.murder.findLocation(target, false)
+	}
+	if dest == nil {
+		fmt.Printf("There's nowhere called '%s'. Type 'map' to see where you can go.\n", target)
+		return
+	}
+	if dest.Name == here.Name {
+		fmt.Printf("You're already in the %s.\n", here.Name)
+		return
+	}
+
+	path := e.murder.route(here.Name, dest.Name)
+	if path == nil {
+		fmt.Printf("You can't find a way from the %s to the %s.\n", here.Name, dest.Name)
+		return
+	}
+	if len(path) > 1 {
+		fmt.Printf("You make your way through the %s...\n", strings.Join(path[:len(path)-1], ", the "))
+	}
+
+	e.room = dest.Name
+	e.look()
+}
+
+// look describes the room the detective is standing in
+func (e *Engine) look() {
+	loc := e.currentLocation()
+	if loc == nil {
+		fmt.Printf("The crime scene is the %s. Type 'examine %s' to search it.\n", e.murder.Location, strings.ToLower(e.murder.Location))
+		return
+	}
+
+	fmt.Printf("\n🚪 %s\n%s\n", loc.Name, loc.Description)
+	if loc.Ambience != "" {
+		fmt.Printf("🔊 %s\n", loc.Ambience)
+	}
+	if strings.EqualFold(loc.Name, e.murder.Location) {
+		fmt.Println("☠️  This is where the body was found.")
+	}
+
+	if people := e.charactersIn(loc.Name); len(people) > 0 {
+		fmt.Printf("Here: %s\n", strings.Join(people, ", "))
+	} else {
+		fmt.Println("There's no one here.")
+	}
+
+	var found []string
+	for i := range e.murder.Evidence {
+		if ev := &e.murder.Evidence[i]; strings.EqualFold(ev.Location, loc.Name) && e.hasFound(ev) {
+			found = append(found, ev.Name)
+		}
+	}
+	if len(found) > 0 {
+		fmt.Printf("You found here: %s\n", strings.Join(found, ", "))
+	}
+
+	fmt.Printf("Exits: %s\n\n", strings.Join(e.murder.exits(loc.Name), ", "))
+
+	e.playAmbience(loc.Ambience)
+}
+
+// showMap lists every room, who is in it and where it leads
+func (e *Engine) showMap() {
+	here := e.currentLocation()
+	if here == nil {
+		fmt.Println("This mystery has no map. Everyone is within reach, so just interview them.")
+		return
+	}
+
+	fmt.Printf("\n🗺️  %s\n", e.murder.Title)
+	for _, loc := range e.murder.Locations {
+		marker := "  "
+		switch {
+		case loc.Name == here.Name:
+			marker = "📍"
+		case strings.EqualFold(loc.Name, e.murder.Location):
+			marker = "☠️ "
+		}
+
+		fmt.Printf("  %s %s → %s\n", marker, loc.Name, strings.Join(e.murder.exits(loc.Name), ", "))
+		if people := e.charactersIn(loc.Name); len(people) > 0 {
+			fmt.Printf("       %s\n", strings.Join(people, ", "))
+		}
+	}
+	fmt.Println("\n📍 you are here  ☠️  crime scene")
+	fmt.Println()
+}
+
+// canSearch reports whether the detective is close enough to search a place.
+// Places that aren't rooms on the map can be searched from anywhere.
+func (e *Engine) canSearch(place string) bool {
+	here := e.currentLocation()
+	if here == nil || e.murder.findLocation(place, true) == nil {
+		return true
+	}
+	return strings.EqualFold(here.Name, place)
+}
+
+// unreachable lists rooms the detective can't walk to from the first room
+func (m Murder) unreachable() []string {
+	if len(m.Locations) == 0 {
+		return nil
+	}
+
+	var rooms []string
+	for _, loc := range m.Locations[1:] {
+		if m.route(m.Locations[0].Name, loc.Name) == nil {
+			rooms = append(rooms, loc.Name)
+		}
+	}
+	return rooms
+}
@@ -0,0 +1,154 @@
+package game
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// manor is testMurder laid out as a house, with a cellar no one can reach
+func manor() Murder {
+	m := testMurder()
+	m.Intro = "The gardener was found dead in the greenhouse."
+	m.Locations = []Location{
+		{Name: "Hall", Description: "Draughty", Exits: []string{"Parlour", "Greenhouse"}, Characters: []string{"Vera"}},
+		{Name: "Parlour", Description: "Cluttered", Exits: []string{"Study"}},
+		{Name: "Study", Description: "Locked from inside"},
+		{Name: "Greenhouse", Description: "Humid", Characters: []string{"Percy Lane"}, Ambience: "Rain drums on the glass."},
+		{Name: "Cellar", Description: "Damp"},
+	}
+	m.Evidence = []Evidence{{Name: "Pruning Shears", Description: "Wiped clean", Location: "Greenhouse"}}
+	return m
+}
+
+func TestExits(t *testing.T) {
+	m := manor()
+
+	tests := map[string][]string{
+		"Hall":       {"Parlour", "Greenhouse"},
+		"Parlour":    {"Hall", "Study"}, // declared by the hall
+		"study":      {"Parlour"},
+		"Greenhouse": {"Hall"},
+		"Cellar":     nil,
+	}
+	for room, want := range tests {
+		if got := m.exits(room); !slices.Equal(got, want) {
+			t.Errorf("exits(%q) = %v, want %v", room, got, want)
+		}
+	}
+}
+
+func TestRoute(t *testing.T) {
+	m := manor()
+
+	tests := []struct {
+		from, to string
+		want     []string
+	}{
+		{from: "Hall", to: "Parlour", want: []string{"Parlour"}},
+		{from: "Hall", to: "Study", want: []string{"Parlour", "Study"}},
+		{from: "Study", to: "Greenhouse", want: []string{"Parlour", "Hall", "Greenhouse"}},
+		{from: "study", to: "hall", want: []string{"Parlour", "Hall"}},
+		{from: "Hall", to: "Cellar", want: nil},
+		{from: "Cellar", to: "Hall", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := m.route(tt.from, tt.to); !slices.Equal(got, tt.want) {
+			t.Errorf("route(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	if got := manor().unreachable(); !slices.Equal(got, []string{"Cellar"}) {
+		t.Errorf("got %v, want [Cellar]", got)
+	}
+	if got := testMurder().unreachable(); got != nil {
+		t.Errorf("a mystery without rooms has unreachable %v", got)
+	}
+}
+
+func TestLocationProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(m *Murder)
+		path     string
+		severity Severity
+	}{
+		{name: "unknown exit", change: func(m *Murder) { m.Locations[1].Exits = append(m.Locations[1].Exits, "Attic") }, path: "locations[1].exits[1]", severity: SeverityError},
+		{name: "exit to itself", change: func(m *Murder) { m.Locations[2].Exits = []string{"study"} }, path: "locations[2].exits[0]", severity: SeverityError},
+		{name: "duplicate room", change: func(m *Murder) { m.Locations[4].Name = "hall" }, path: "locations[4].name", severity: SeverityError},
+		{name: "unknown character", change: func(m *Murder) { m.Locations[2].Characters = []string{"The Butler"} }, path: "locations[2].characters[0]", severity: SeverityError},
+		{name: "character in two rooms", change: func(m *Murder) { m.Locations[2].Characters = []string{"Vera Holt"} }, path: "locations[2].characters[0]", severity: SeverityError},
+		{name: "crime scene not a room", change: func(m *Murder) { m.Location = "Orchard" }, path: "location", severity: SeverityError},
+		{name: "evidence outside the map", change: func(m *Murder) { m.Evidence[0].Location = "Potting Bench" }, path: "evidence[0].location", severity: SeverityWarning},
+	}
+
+	isLocationProblem := func(p Problem) bool {
+		return strings.HasPrefix(p.Path, "location") || strings.HasPrefix(p.Path, "evidence")
+	}
+
+	// the only thing wrong with the manor is its cellar
+	m := manor()
+	for _, p := range m.Problems() {
+		if isLocationProblem(p) && !(p.Path == "locations" && strings.Contains(p.Message, "Cellar")) {
+			t.Errorf("manor: unexpected %s", p)
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := manor()
+			tt.change(&m)
+
+			problems := m.Problems()
+			if !slices.ContainsFunc(problems, func(p Problem) bool { return p.Path == tt.path && p.Severity == tt.severity }) {
+				t.Errorf("no %s at %s in %v", tt.severity, tt.path, problems)
+			}
+		})
+	}
+}
+
+func TestWalkingInPlaysAmbience(t *testing.T) {
+	m := manor()
+	m.NarratorTTS = []TTS{{Engine: "speaker", Model: "narrator"}}
+	voice := &speaker{started: make(chan struct{}), release: make(chan struct{})}
+
+	e := testEngine(t, m)
+	e.tts = voice
+
+	// the ambience plays while the detective carries on, so this doesn't wait for it
+	e.goTo("greenhouse")
+	select {
+	case <-voice.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the greenhouse's ambience was never played")
+	}
+
+	voice.mu.Lock()
+	if voice.last.Text != "Rain drums on the glass." || voice.last.Model != "narrator" || voice.last.Emotions != ambienceEmotion {
+		t.Errorf("ambience read as %+v, want the greenhouse's in the narrator's voice", voice.last)
+	}
+	voice.mu.Unlock()
+
+	// leaving cuts it off, and the hall is silent
+	e.goTo("hall")
+	if e.ambience != nil {
+		t.Error("the hall has ambience playing")
+	}
+
+	// without a narrator voice for the engine it is only printed
+	e.murder.NarratorTTS = nil
+	e.goTo("greenhouse")
+	if e.ambience != nil {
+		t.Error("ambience played without a narrator voice")
+	}
+
+	voice.mu.Lock()
+	defer voice.mu.Unlock()
+	if len(voice.said) != 1 {
+		t.Errorf("said %v, want only the first visit's ambience", voice.said)
+	}
+}
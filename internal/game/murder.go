@@ -1,18 +1,80 @@
 package game
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/schollz/closestmatch"
 )
 
 // Murder scenario loaded from JSON
 type Murder struct {
 	Title       string      `json:"title"`
+	Victim      string      `json:"victim"`
 	Killer      string      `json:"killer"`
 	Weapon      string      `json:"weapon"`
 	Location    string      `json:"location"`
+	Motive      string      `json:"motive"`
 	Intro       string      `json:"introduction"`
 	NarratorTTS []TTS       `json:"narrator_tts,omitempty"`
 	Characters  []Character `json:"characters"`
+	Evidence    []Evidence  `json:"evidence,omitempty"`
+	Locations   []Location  `json:"locations,omitempty"`
+}
+
+// CaseView is the murder as seen by a single character. Only the killer
+// knows the solution; everyone else knows what the introduction made public
+// plus whatever their own knowledge says.
+type CaseView struct {
+	Victim    string
+	FoundIn   string
+	Public    string
+	Knowledge []string
+	Room      string // where the character is now, if the mystery has a map
+
+	IsKiller bool
+	Weapon   string
+	Motive   string
+}
+
+// ViewFor scopes the murder down to what a character can know
+func (m Murder) ViewFor(c *Character) CaseView {
+	view := CaseView{
+		Victim:    m.Victim,
+		FoundIn:   m.Location,
+		Public:    m.Intro,
+		Knowledge: c.Knowledge,
+		Room:      m.locationOf(c),
+	}
+
+	if killer := m.findCharacterByName(m.Killer); killer != nil && strings.EqualFold(killer.Name, c.Name) {
+		view.IsKiller = true
+		view.Weapon = m.Weapon
+		view.Motive = m.Motive
+	}
+
+	return view
+}
+
+func (v CaseView) String() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("- Victim: %s\n", v.Victim))
+	b.WriteString(fmt.Sprintf("- Victim found in: %s\n", v.FoundIn))
+	b.WriteString(fmt.Sprintf("- What everyone has heard: %s\n", v.Public))
+	b.WriteString(fmt.Sprintf("- Your knowledge about the case: %s\n", strings.Join(v.Knowledge, "; ")))
+	if v.Room != "" {
+		b.WriteString(fmt.Sprintf("- Where you are now, talking to the detective: %s\n", v.Room))
+	}
+
+	if v.IsKiller {
+		b.WriteString(fmt.Sprintf("- THE TRUTH ONLY YOU KNOW: you are the killer. You killed %s with the %s in the %s. Your motive: %s\n",
+			v.Victim, v.Weapon, v.FoundIn, v.Motive))
+		b.WriteString("- Never confess. Keep to your cover story and deflect suspicion, but you may slip up under sustained pressure\n")
+	} else {
+		b.WriteString("- You do not know who the killer is. Anything beyond your own knowledge is speculation and must be presented as such\n")
+	}
+
+	return b.String()
 }
 
 func (m *Murder) closesCharacterMatches() *closestmatch.ClosestMatch {
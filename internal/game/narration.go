@@ -0,0 +1,83 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"gofigure/internal/tts"
+	"time"
+)
+
+// how the narrator delivers the opening of a case
+const (
+	welcomeEmotion  = "Welcoming and friendly"
+	introEmotion    = "Authorative, calm with a tone of mischief"
+	ambienceEmotion = "Hushed and atmospheric"
+)
+
+func (m Murder) welcomeMessage() string {
+	return fmt.Sprintf("Welcome Detective! You are investigating: %s", m.Title)
+}
+
+// narratorModel returns the narrator's voice for the given tts engine
+func (m Murder) narratorModel(engine string) string {
+	for _, ttsOption := range m.NarratorTTS {
+		if ttsOption.Engine == engine {
+			return ttsOption.Model
+		}
+	}
+
+	return ""
+}
+
+// PrewarmNarration renders a mystery's welcome and introduction with exactly
+// the voice and delivery the game uses, so a cached engine can play them at once
+func PrewarmNarration(ctx context.Context, synth tts.Synthesizer, engine, filename string) error {
+	m, err := loadMystery(filename)
+	if err != nil {
+		return err
+	}
+
+	model := m.narratorModel(engine)
+	if model == "" {
+		return fmt.Errorf("%s has no narrator voice for the %s engine", filename, engine)
+	}
+
+	lines := []struct{ text, emotion string }{
+		{m.welcomeMessage(), welcomeEmotion},
+		{m.Intro, introEmotion},
+	}
+	for _, loc := range m.Locations {
+		if loc.Ambience != "" {
+			lines = append(lines, struct{ text, emotion string }{loc.Ambience, ambienceEmotion})
+		}
+	}
+
+	for _, line := range lines {
+		lineCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		_, err := synth.Synthesize(lineCtx, tts.Line{Text: line.text, Emotions: line.emotion, Model: model})
+		cancel()
+
+		if err != nil {
+			return fmt.Errorf("failed to render narration for %s: %w", filename, err)
+		}
+	}
+	return nil
+}
+
+// playAmbience reads a room's ambience in the narrator's voice, when the
+// mystery has one for the tts engine, without holding up the prompt. Whatever
+// the last room was still saying is cut off.
+func (e *Engine) playAmbience(text string) {
+	if e.ambience != nil {
+		e.ambience.cancel()
+		e.ambience = nil
+	}
+
+	model := e.findNarratorTtsModel()
+	if text == "" || model == "" {
+		return
+	}
+	e.ambience = newSpeechQueue(e.tts, model, nil)
+	e.ambience.add(text, ambienceEmotion)
+	e.ambience.end()
+}
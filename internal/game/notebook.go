@@ -0,0 +1,198 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gofigure/internal/llm"
+	"strings"
+	"sync"
+	"time"
+)
+
+type EntryKind string
+
+const (
+	EntryTestimony EntryKind = "testimony" // a character's reply, recorded verbatim
+	EntryNote      EntryKind = "note"      // written by the detective
+	EntryClue      EntryKind = "clue"      // factual claim extracted from testimony
+	EntryEvidence  EntryKind = "evidence"  // physical evidence the detective has found
+)
+
+// NotebookEntry is a single line in the detective's notebook
+type NotebookEntry struct {
+	Kind      EntryKind `json:"kind"`
+	Character string    `json:"character,omitempty"`
+	Question  string    `json:"question,omitempty"`
+	Text      string    `json:"text"`
+	Who       string    `json:"who,omitempty"`
+	Where     string    `json:"where,omitempty"`
+	When      string    `json:"when,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Notebook collects testimony, notes and clues gathered during an investigation.
+// Clues are extracted in the background, so all access is guarded.
+type Notebook struct {
+	mu      sync.Mutex
+	entries []*NotebookEntry
+}
+
+func NewNotebook() *Notebook {
+	return &Notebook{}
+}
+
+func (n *Notebook) add(entry *NotebookEntry) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.entries = append(n.entries, entry)
+}
+
+func (n *Notebook) RecordReply(character, question string, reply *llm.CharacterReply) {
+	n.add(&NotebookEntry{
+		Kind:      EntryTestimony,
+		Character: character,
+		Question:  question,
+		Text:      reply.Response,
+		Timestamp: time.Now(),
+	})
+}
+
+func (n *Notebook) AddNote(text string) {
+	n.add(&NotebookEntry{Kind: EntryNote, Text: text, Timestamp: time.Now()})
+}
+
+func (n *Notebook) AddClue(character string, clue Clue) {
+	n.add(&NotebookEntry{
+		Kind:      EntryClue,
+		Character: character,
+		Text:      clue.Claim,
+		Who:       clue.Who,
+		Where:     clue.Where,
+		When:      clue.When,
+		Timestamp: time.Now(),
+	})
+}
+
+func (n *Notebook) AddEvidence(ev *Evidence) {
+	n.add(&NotebookEntry{
+		Kind:      EntryEvidence,
+		Text:      fmt.Sprintf("%s: %s", ev.Name, ev.Description),
+		Where:     ev.Location,
+		Timestamp: time.Now(),
+	})
+}
+
+// Entries returns a snapshot of every entry in the order it was written
+func (n *Notebook) Entries() []*NotebookEntry {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]*NotebookEntry(nil), n.entries...)
+}
+
+// ForCharacter returns testimony and clues attributed to the named character
+func (n *Notebook) ForCharacter(name string) []*NotebookEntry {
+	var found []*NotebookEntry
+	for _, entry := range n.Entries() {
+		if strings.EqualFold(entry.Character, name) {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// Search returns entries where any field contains the query, case-insensitively
+func (n *Notebook) Search(query string) []*NotebookEntry {
+	query = strings.ToLower(strings.TrimSpace(query))
+	var found []*NotebookEntry
+	for _, entry := range n.Entries() {
+		fields := []string{entry.Character, entry.Question, entry.Text, entry.Who, entry.Where, entry.When}
+		for _, f := range fields {
+			if strings.Contains(strings.ToLower(f), query) {
+				found = append(found, entry)
+				break
+			}
+		}
+	}
+	return found
+}
+
+func (n *Notebook) restore(entries []*NotebookEntry) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.entries = entries
+}
+
+func (e *NotebookEntry) String() string {
+	ts := e.Timestamp.Format("15:04")
+	switch e.Kind {
+	case EntryNote:
+		return fmt.Sprintf("[%s] 📝 %s", ts, e.Text)
+	case EntryEvidence:
+		return fmt.Sprintf("[%s] 🧾 %s", ts, e.Text)
+	case EntryClue:
+		var details []string
+		if e.Who != "" {
+			details = append(details, "who: "+e.Who)
+		}
+		if e.Where != "" {
+			details = append(details, "where: "+e.Where)
+		}
+		if e.When != "" {
+			details = append(details, "when: "+e.When)
+		}
+		s := fmt.Sprintf("[%s] 🔎 %s: %s", ts, e.Character, e.Text)
+		if len(details) > 0 {
+			s += fmt.Sprintf(" (%s)", strings.Join(details, ", "))
+		}
+		return s
+	default:
+		return fmt.Sprintf("[%s] 🗣️ %s: %s", ts, e.Character, e.Text)
+	}
+}
+
+// Clue is a discrete factual claim made by a character
+type Clue struct {
+	Claim string `json:"claim"`
+	Who   string `json:"who"`
+	Where string `json:"where"`
+	When  string `json:"when"`
+}
+
+// extractClues asks the llm to break a character's answer into factual claims
+func extractClues(ctx context.Context, llmClient llm.LLM, character, question, answer string) ([]Clue, error) {
+	prompt := fmt.Sprintf(`You are assisting a detective by keeping their notebook tidy.
+
+%s was asked: "%s"
+%s answered: "%s"
+
+Extract every discrete factual claim made in the answer (who did what, where, and when).
+Ignore opinions, emotions and small talk. Leave a field empty if the answer does not say.
+Reply only in this JSON structure {"clues": [{"claim": string, "who": string, "where": string, "when": string}]}`,
+		character, question, character, answer)
+
+	resp, err := llmClient.GenerateResponse(ctx, prompt)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := llm.ExtractJSON(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var extracted struct {
+		Clues []Clue `json:"clues"`
+	}
+	if err := json.Unmarshal([]byte(obj), &extracted); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal clues: %w", err)
+	}
+
+	var clues []Clue
+	for _, clue := range extracted.Clues {
+		if strings.TrimSpace(clue.Claim) != "" {
+			clues = append(clues, clue)
+		}
+	}
+	return clues, nil
+}
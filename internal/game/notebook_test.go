@@ -0,0 +1,97 @@
+package game
+
+import (
+	"context"
+	"gofigure/internal/llm"
+	"strings"
+	"testing"
+)
+
+func testNotebook() *Notebook {
+	n := NewNotebook()
+	n.RecordReply("Percy Lane", "What did you hear?", &llm.CharacterReply{Response: "Glass breaking, around midnight."})
+	n.RecordReply("Mrs. Oduya", "Where were you?", &llm.CharacterReply{Response: "Making cocoa in the kitchen."})
+	n.AddClue("Percy Lane", Clue{Claim: "Heard glass break", When: "midnight", Where: "greenhouse"})
+	n.AddNote("Check the greenhouse door")
+	return n
+}
+
+func TestNotebookForCharacter(t *testing.T) {
+	n := testNotebook()
+
+	got := n.ForCharacter("percy lane")
+	if len(got) != 2 {
+		t.Fatalf("got %d entries for Percy, want his testimony and clue", len(got))
+	}
+	if got[0].Kind != EntryTestimony || got[1].Kind != EntryClue {
+		t.Errorf("got kinds %s, %s; want testimony then clue", got[0].Kind, got[1].Kind)
+	}
+	if got := n.ForCharacter("Percy"); len(got) != 0 {
+		t.Errorf("a partial name matched %d entries", len(got))
+	}
+}
+
+func TestNotebookSearch(t *testing.T) {
+	n := testNotebook()
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"GREENHOUSE", 2}, // the clue's place and the note
+		{"midnight", 2},   // testimony text and the clue's time
+		{"where were you", 1},
+		{"oduya", 1},
+		{"  cocoa  ", 1},
+		{"candlestick", 0},
+	}
+	for _, tt := range tests {
+		if got := n.Search(tt.query); len(got) != tt.want {
+			t.Errorf("Search(%q) found %d entries, want %d", tt.query, len(got), tt.want)
+		}
+	}
+}
+
+func TestExtractClues(t *testing.T) {
+	fake := &fakeLLM{reply: "Here you go:\n```json\n" + `{"clues": [
+		{"claim": "Heard glass break", "who": "Percy Lane", "where": "greenhouse", "when": "midnight"},
+		{"claim": "  ", "who": "nobody"}
+	]}` + "\n```"}
+
+	clues, err := extractClues(context.Background(), fake, "Percy Lane", "What did you hear?", "Glass, at midnight.")
+	if err != nil {
+		t.Fatalf("extractClues: %v", err)
+	}
+	if len(clues) != 1 || clues[0] != (Clue{Claim: "Heard glass break", Who: "Percy Lane", Where: "greenhouse", When: "midnight"}) {
+		t.Errorf("got %+v, want the one non-empty clue", clues)
+	}
+	if !strings.Contains(fake.prompts[0], "Glass, at midnight.") {
+		t.Error("the answer was not in the prompt")
+	}
+
+	if _, err := extractClues(context.Background(), &fakeLLM{reply: "no idea"}, "Percy Lane", "q", "a"); err == nil {
+		t.Error("a reply without JSON was accepted")
+	}
+}
+
+func TestLateCluesStayWithTheirGame(t *testing.T) {
+	e := testEngine(t, testMurder())
+	e.llm = &fakeLLM{reply: `{"clues": [{"claim": "Heard glass break"}]}`}
+	if err := e.saveGame("before"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the answer is filed, then a load replaces the game before its clues arrive
+	old := e.notebook
+	if err := e.loadGame("before"); err != nil {
+		t.Fatal(err)
+	}
+	e.fileClues(old, "Percy Lane", "What did you hear?", "Glass breaking.")
+
+	if got := e.notebook.Search("glass"); len(got) != 0 {
+		t.Errorf("a clue from the abandoned game reached the loaded notebook: %v", got)
+	}
+	if got := old.Search("glass"); len(got) != 1 {
+		t.Errorf("clue not filed with its own game, got %d entries", len(got))
+	}
+}
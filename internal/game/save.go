@@ -0,0 +1,145 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// saveVersion is bumped whenever the layout of SaveFile changes
+const saveVersion = 5
+
+// SaveFile is an investigation in progress, as written to disk
+type SaveFile struct {
+	Version     int       `json:"version"`
+	SavedAt     time.Time `json:"saved_at"`
+	MysteryFile string    `json:"mystery_file,omitempty"`
+	Murder      Murder    `json:"murder"`
+
+	// Conversations holds each character's message history keyed by character name
+	Conversations  map[string][]*Message `json:"conversations"`
+	Accusations    []string              `json:"accusations,omitempty"`
+	Evidence       []string              `json:"evidence,omitempty"` // names of the evidence found, since version 2
+	Room           string                `json:"room,omitempty"`     // where the detective is, since version 3
+	Unlocked       []string              `json:"unlocked,omitempty"` // secrets characters may confess, since version 4
+	Notebook       []*NotebookEntry      `json:"notebook,omitempty"`
+	ElapsedSeconds int64                 `json:"elapsed_seconds"`
+}
+
+func (e *Engine) saveGame(slot string) error {
+	path, err := savePath(e.config.Game.SaveDir, slot)
+	if err != nil {
+		return err
+	}
+
+	save := SaveFile{
+		Version:        saveVersion,
+		SavedAt:        time.Now(),
+		MysteryFile:    e.mysteryFile,
+		Murder:         e.murder,
+		Conversations:  map[string][]*Message{},
+		Accusations:    e.accusations,
+		Evidence:       e.evidence,
+		Room:           e.room,
+		Unlocked:       e.unlocked,
+		Notebook:       e.notebook.Entries(),
+		ElapsedSeconds: int64(e.elapsedTime().Seconds()),
+	}
+
+	for _, char := range e.murder.Characters {
+		if len(char.Conversation) > 0 {
+			save.Conversations[char.Name] = char.Conversation
+		}
+	}
+
+	data, err := json.MarshalIndent(save, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode save file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create save directory: %w", err)
+	}
+
+	// write to a temporary file first so a crash never leaves a half-written slot
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write save file: %w", err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (e *Engine) loadGame(slot string) error {
+	path, err := savePath(e.config.Game.SaveDir, slot)
+	if err != nil {
+		return err
+	}
+
+	save, err := readSaveFile(path)
+	if err != nil {
+		return err
+	}
+
+	murder := save.Murder
+	for i := range murder.Characters {
+		murder.Characters[i].Conversation = save.Conversations[murder.Characters[i].Name]
+	}
+
+	e.murder = murder
+	e.mysteryFile = save.MysteryFile
+	e.accusations = save.Accusations
+	e.evidence = save.Evidence
+	e.room = save.Room
+	e.unlocked = save.Unlocked
+	e.notebook = NewNotebook()
+	e.notebook.restore(save.Notebook)
+	e.elapsed = time.Duration(save.ElapsedSeconds) * time.Second
+	e.startedAt = time.Now()
+	e.resumed = true
+	e.refreshPhraseHints()
+
+	return nil
+}
+
+func readSaveFile(path string) (*SaveFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read save file: %w", err)
+	}
+
+	var save SaveFile
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, fmt.Errorf("failed to decode save file: %w", err)
+	}
+
+	if save.Version < 1 || save.Version > saveVersion {
+		return nil, fmt.Errorf("unsupported save file version %d (supported: 1-%d)", save.Version, saveVersion)
+	}
+
+	return &save, nil
+}
+
+// savePath maps a slot name to its file, rejecting anything that would escape the save directory
+func savePath(dir, slot string) (string, error) {
+	slot = strings.TrimSpace(slot)
+	if slot == "" || slot != filepath.Base(slot) || strings.HasPrefix(slot, ".") {
+		return "", fmt.Errorf("invalid save slot name '%s'", slot)
+	}
+
+	if dir == "" {
+		dir = "saves"
+	}
+
+	return filepath.Join(dir, slot+".json"), nil
+}
+
+func (e *Engine) elapsedTime() time.Duration {
+	if e.startedAt.IsZero() {
+		return e.elapsed
+	}
+	return e.elapsed + time.Since(e.startedAt)
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	e := testEngine(t, testMurder())
+	e.mysteryFile = "greenhouse.json"
+
+	char := &e.murder.Characters[1]
+	if _, err := char.AskQuestion(context.Background(), "what did you hear?", e.murder, e.llm); err != nil {
+		t.Fatal(err)
+	}
+	e.accusations = []string{"percy shears greenhouse"}
+	e.notebook.AddNote("Percy heard glass break")
+	e.elapsed = 90 * time.Second
+
+	if err := e.saveGame("slot1"); err != nil {
+		t.Fatalf("saveGame: %v", err)
+	}
+
+	loaded := testEngine(t, Murder{})
+	loaded.config = e.config
+	if err := loaded.loadGame("slot1"); err != nil {
+		t.Fatalf("loadGame: %v", err)
+	}
+
+	if loaded.murder.Title != e.murder.Title || loaded.mysteryFile != "greenhouse.json" {
+		t.Errorf("loaded %q from %q, want %q from greenhouse.json", loaded.murder.Title, loaded.mysteryFile, e.murder.Title)
+	}
+	if !loaded.resumed {
+		t.Error("loaded game is not marked as resumed")
+	}
+	if got := loaded.murder.Characters[1].Conversation; len(got) != len(char.Conversation) || got[len(got)-1].Content != char.Conversation[len(got)-1].Content {
+		t.Errorf("conversation with %s not restored: %+v", char.Name, got)
+	}
+	if len(loaded.murder.Characters[0].Conversation) != 0 {
+		t.Error("a character who was never interviewed has a conversation")
+	}
+	if len(loaded.accusations) != 1 || loaded.accusations[0] != "percy shears greenhouse" {
+		t.Errorf("accusations = %v", loaded.accusations)
+	}
+	if notes := loaded.notebook.Search("glass break"); len(notes) == 0 {
+		t.Error("notebook not restored")
+	}
+	if loaded.elapsedTime() < 90*time.Second {
+		t.Errorf("elapsed time %s, want at least 1m30s", loaded.elapsedTime())
+	}
+}
+
+func TestLoadRejectsUnsupportedVersions(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, version := range []int{0, saveVersion + 1} {
+		path := filepath.Join(dir, "slot.json")
+		data, _ := json.Marshal(SaveFile{Version: version, Murder: testMurder()})
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := readSaveFile(path)
+		if err == nil || !strings.Contains(err.Error(), "unsupported save file version") {
+			t.Errorf("version %d: got %v, want an unsupported version error", version, err)
+		}
+	}
+}
+
+func TestSavePathRejectsEscapes(t *testing.T) {
+	for _, slot := range []string{"", "../outside", "a/b", ".hidden"} {
+		if _, err := savePath("saves", slot); err == nil {
+			t.Errorf("slot %q accepted", slot)
+		}
+	}
+	if path, err := savePath("", "case1"); err != nil || path != filepath.Join("saves", "case1.json") {
+		t.Errorf("savePath(\"\", case1) = %q, %v", path, err)
+	}
+}
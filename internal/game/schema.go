@@ -0,0 +1,285 @@
+package game
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MysterySchema is the published JSON Schema for mystery files
+//
+//go:embed mystery.schema.json
+var MysterySchema []byte
+
+// jsonSchema is the subset of JSON Schema used by mystery.schema.json
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref"`
+	Defs                 map[string]*jsonSchema `json:"$defs"`
+	Type                 schemaTypes            `json:"type"`
+	Required             []string               `json:"required"`
+	Properties           map[string]*jsonSchema `json:"properties"`
+	AdditionalProperties *additionalProperties  `json:"additionalProperties"`
+	Items                *jsonSchema            `json:"items"`
+	MinLength            *int                   `json:"minLength"`
+	MinItems             *int                   `json:"minItems"`
+	Minimum              *float64               `json:"minimum"`
+	Enum                 []string               `json:"enum"`
+}
+
+// additionalProperties accepts both false and a schema for the values of unlisted keys
+type additionalProperties struct {
+	allowed bool
+	schema  *jsonSchema
+}
+
+func (a *additionalProperties) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.allowed); err == nil {
+		return nil
+	}
+
+	a.allowed = true
+	return json.Unmarshal(data, &a.schema)
+}
+
+// schemaTypes accepts both "type": "string" and "type": ["string", "object"]
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+// validateSchema checks a mystery document against MysterySchema
+func validateSchema(data []byte) ([]Problem, error) {
+	var root jsonSchema
+	if err := json.Unmarshal(MysterySchema, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse mystery schema: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	v := schemaValidator{root: &root}
+	v.validate(&root, doc, "")
+	return v.problems, nil
+}
+
+type schemaValidator struct {
+	root     *jsonSchema
+	problems []Problem
+}
+
+func (v *schemaValidator) fail(path, format string, args ...any) {
+	v.problems = append(v.problems, Problem{
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+		Severity: SeverityError,
+	})
+}
+
+func (v *schemaValidator) resolve(s *jsonSchema) *jsonSchema {
+	for s != nil && s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/$defs/")
+		s = v.root.Defs[name]
+	}
+	return s
+}
+
+func (v *schemaValidator) validate(s *jsonSchema, value any, path string) {
+	s = v.resolve(s)
+	if s == nil {
+		return
+	}
+
+	if len(s.Type) > 0 && !matchesType(s.Type, value) {
+		v.fail(path, "expected %s, got %s", strings.Join(s.Type, " or "), jsonTypeOf(value))
+		return
+	}
+
+	switch val := value.(type) {
+	case string:
+		if s.MinLength != nil && len(strings.TrimSpace(val)) < *s.MinLength {
+			v.fail(path, "must not be empty")
+		}
+		if len(s.Enum) > 0 && !containsFold(s.Enum, val) {
+			v.fail(path, "'%s' is not one of %v", val, s.Enum)
+		}
+
+	case json.Number:
+		if n, err := val.Float64(); err == nil && s.Minimum != nil && n < *s.Minimum {
+			v.fail(path, "must be at least %v", *s.Minimum)
+		}
+
+	case []any:
+		if s.MinItems != nil && len(val) < *s.MinItems {
+			v.fail(path, "must have at least %d item(s)", *s.MinItems)
+		}
+		for i, item := range val {
+			v.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+
+	case map[string]any:
+		for _, req := range s.Required {
+			if _, ok := val[req]; !ok {
+				v.fail(joinPath(path, req), "is required")
+			}
+		}
+
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			prop, ok := s.Properties[k]
+			if !ok {
+				switch extra := s.AdditionalProperties; {
+				case extra == nil:
+				case !extra.allowed:
+					v.fail(joinPath(path, k), "unknown field")
+				case extra.schema != nil:
+					v.validate(extra.schema, val[k], joinPath(path, k))
+				}
+				continue
+			}
+			v.validate(prop, val[k], joinPath(path, k))
+		}
+	}
+}
+
+func matchesType(types schemaTypes, value any) bool {
+	actual := jsonTypeOf(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonTypeOf(value any) string {
+	switch val := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := val.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexLines maps every field path in a JSON document to the line it starts on
+func indexLines(data []byte) map[string]int {
+	lines := map[string]int{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	lineAt := func(offset int64) int {
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+
+	mark := func(path string) {
+		if _, ok := lines[path]; !ok {
+			lines[path] = lineAt(dec.InputOffset())
+		}
+	}
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		mark(path)
+
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child := joinPath(path, fmt.Sprint(key))
+				mark(child)
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+			return err
+
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+			return err
+		}
+		return nil
+	}
+
+	_ = walk("")
+	return lines
+}
+
+// lineFor finds the line of a path, falling back to its closest parent
+// when the field itself is missing from the document
+func lineFor(lines map[string]int, path string) int {
+	for {
+		if line, ok := lines[path]; ok {
+			return line
+		}
+		cut := strings.LastIndexAny(path, ".[")
+		if cut < 0 {
+			return lines[""]
+		}
+		path = path[:cut]
+	}
+}
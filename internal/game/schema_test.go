@@ -0,0 +1,202 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// checkSchema validates a document against a schema written for the test
+func checkSchema(t *testing.T, schema, doc string) []Problem {
+	t.Helper()
+
+	var root jsonSchema
+	if err := json.Unmarshal([]byte(schema), &root); err != nil {
+		t.Fatalf("bad test schema: %v", err)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(doc))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		t.Fatalf("bad test document: %v", err)
+	}
+
+	v := schemaValidator{root: &root}
+	v.validate(&root, value, "")
+	return v.problems
+}
+
+func TestSchemaKeywords(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+		want   []string // "path: message" of each problem
+	}{
+		{"type", `{"type": "string"}`, `3`, []string{": expected string, got integer"}},
+		{"type list", `{"type": ["string", "object"]}`, `{}`, nil},
+		{"type list mismatch", `{"type": ["string", "object"]}`, `[]`, []string{": expected string or object, got array"}},
+		{"integer is a number", `{"type": "number"}`, `2`, nil},
+		{"number is not an integer", `{"type": "integer"}`, `2.5`, []string{": expected integer, got number"}},
+		{"required", `{"type": "object", "required": ["name"]}`, `{}`, []string{"name: is required"}},
+		{"properties", `{"properties": {"age": {"type": "integer"}}}`, `{"age": "ten"}`, []string{"age: expected integer, got string"}},
+		{"closed object", `{"additionalProperties": false}`, `{"extra": 1}`, []string{"extra: unknown field"}},
+		{"open object", `{}`, `{"extra": 1}`, nil},
+		{"additional properties schema", `{"additionalProperties": {"type": "number"}}`, `{"a": 1, "b": "x"}`, []string{"b: expected number, got string"}},
+		{"items", `{"items": {"type": "string"}}`, `["a", 2]`, []string{"[1]: expected string, got integer"}},
+		{"minItems", `{"minItems": 2}`, `["a"]`, []string{": must have at least 2 item(s)"}},
+		{"minLength ignores whitespace", `{"minLength": 1}`, `"  "`, []string{": must not be empty"}},
+		{"minimum", `{"minimum": 0.25}`, `0.1`, []string{": must be at least 0.25"}},
+		{"enum ignores case", `{"enum": ["google", "piper"]}`, `"Piper"`, nil},
+		{"enum", `{"enum": ["google", "piper"]}`, `"espeak"`, []string{": 'espeak' is not one of [google piper]"}},
+		{
+			"ref",
+			`{"properties": {"voice": {"$ref": "#/$defs/voice"}}, "$defs": {"voice": {"required": ["engine"]}}}`,
+			`{"voice": {}}`,
+			[]string{"voice.engine: is required"},
+		},
+		{
+			"nested paths",
+			`{"properties": {"characters": {"items": {"properties": {"knowledge": {"items": {"minLength": 1}}}}}}}`,
+			`{"characters": [{"knowledge": ["a"]}, {"knowledge": ["b", ""]}]}`,
+			[]string{"characters[1].knowledge[1]: must not be empty"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range checkSchema(t, tt.schema, tt.doc) {
+				got = append(got, p.Path+": "+p.Message)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("got problems %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMysterySchemaAcceptsBundledMysteries(t *testing.T) {
+	files, _ := filepath.Glob("../../data/mysteries/*.json")
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		problems, err := validateSchema(data)
+		if err != nil || len(problems) > 0 {
+			t.Errorf("%s: %v %v", filepath.Base(file), err, problems)
+		}
+	}
+}
+
+// brokenMystery has one mistake of each kind, each on a known line
+const brokenMystery = `{
+  "title": "Broken",
+  "introduction": "The gardener was found among the orchids.",
+  "victim": "Old Tom",
+  "killer": "Nobody Here",
+  "weapon": "Shears",
+  "location": "Greenhouse",
+  "motive": "",
+  "characters": [
+    {
+      "name": "Percy",
+      "personality": "Jumpy",
+      "knowledge": ["Heard glass break in the greenhouse", 7],
+      "reliable": "yes",
+      "tts": [{"engine": "espeak"}]
+    }
+  ],
+  "colour": "green"
+}
+`
+
+func TestValidateMysteryFileLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken.json")
+	if err := os.WriteFile(path, []byte(brokenMystery), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int{
+		"motive":                      8,
+		"characters[0].knowledge[1]":  13,
+		"characters[0].reliable":      14,
+		"characters[0].tts[0].engine": 15,
+		"killer":                      5,
+		"colour":                      18,
+		"weapon":                      6,
+	}
+
+	got := map[string]int{}
+	for _, p := range ValidateMysteryFile(path) {
+		if p.File != path {
+			t.Errorf("%s reported against %q", p.Path, p.File)
+		}
+		if _, seen := got[p.Path]; seen {
+			t.Errorf("%s reported twice", p.Path)
+		}
+		got[p.Path] = p.Line
+	}
+
+	for path, line := range want {
+		if got[path] != line {
+			t.Errorf("%s: reported on line %d, want %d", path, got[path], line)
+		}
+	}
+}
+
+func TestValidateMysteryFileSyntaxError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "syntax.json")
+	if err := os.WriteFile(path, []byte("{\n  \"title\": \"x\",\n  \"victim\" \"y\"\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	problems := ValidateMysteryFile(path)
+	if len(problems) != 1 || problems[0].Line != 3 || problems[0].Severity != SeverityError {
+		t.Errorf("got %v, want one error on line 3", problems)
+	}
+}
+
+func TestLineForFallsBackToParent(t *testing.T) {
+	lines := indexLines([]byte("{\n  \"characters\": [\n    {\n      \"name\": \"Percy\"\n    }\n  ]\n}"))
+
+	tests := map[string]int{
+		"":                        1,
+		"characters":              2,
+		"characters[0]":           3,
+		"characters[0].name":      4,
+		"characters[0].knowledge": 3, // missing, so its character
+		"title":                   1, // missing, so the document
+	}
+	for path, want := range tests {
+		if got := lineFor(lines, path); got != want {
+			t.Errorf("lineFor(%q) = %d, want %d", path, got, want)
+		}
+	}
+}
+
+func TestDedupeProblems(t *testing.T) {
+	problems := []Problem{
+		{Path: "killer", Message: "must not be empty", Severity: SeverityError},
+		{Path: "killer", Message: "is required", Severity: SeverityError},
+		{Path: "weapon", Message: "never mentioned", Severity: SeverityWarning},
+		{Path: "weapon", Message: "never mentioned again", Severity: SeverityWarning},
+		{Path: "killer", Message: "typo?", Severity: SeverityWarning},
+	}
+
+	var got bytes.Buffer
+	for _, p := range dedupeProblems(problems) {
+		got.WriteString(p.Path + ": " + p.Message + "\n")
+	}
+
+	// only the first error for a path is kept, warnings always are
+	want := "killer: must not be empty\nweapon: never mentioned\nweapon: never mentioned again\nkiller: typo?\n"
+	if got.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", got.String(), want)
+	}
+}
@@ -0,0 +1,177 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"gofigure/internal/llm"
+	"strings"
+)
+
+// Secret is something a character hides. A plain string in a mystery file is
+// a secret without a trigger, given up only if the model decides the
+// detective has cornered them.
+type Secret struct {
+	Text string `json:"text"`
+
+	// ID names the secret so other secrets can be unlocked after it
+	ID string `json:"id,omitempty"`
+	// Unlock lets the character confess once the detective has done enough
+	Unlock *SecretTrigger `json:"unlock,omitempty"`
+}
+
+// SecretTrigger is what it takes to crack a character. Every condition that
+// is set has to be met. After goes by unlocking rather than confessing, as
+// whether a character actually admits a secret is up to the model.
+type SecretTrigger struct {
+	Keywords  []string `json:"keywords,omitempty"`  // any of these in a question to the character
+	Evidence  []string `json:"evidence,omitempty"`  // any of this evidence shown to the character
+	Questions int      `json:"questions,omitempty"` // at least this many questions put to the character
+	After     string   `json:"after,omitempty"`     // once the secret with this id has been unlocked, whoever it belongs to
+}
+
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*s = Secret{Text: text}
+		return nil
+	}
+
+	type plain Secret
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// MarshalJSON writes a secret without a trigger back as a plain string
+func (s Secret) MarshalJSON() ([]byte, error) {
+	if s.ID == "" && s.Unlock == nil {
+		return json.Marshal(s.Text)
+	}
+
+	type plain Secret
+	return json.Marshal(plain(s))
+}
+
+// key identifies the secret among those the detective has unlocked
+func (s Secret) key() string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return s.Text
+}
+
+// secretTexts lists what a character is hiding
+func (c *Character) secretTexts() []string {
+	var texts []string
+	for _, s := range c.Secrets {
+		texts = append(texts, s.Text)
+	}
+	return texts
+}
+
+// interrogation is everything the detective has put to a character so far,
+// including the turn about to be sent
+type interrogation struct {
+	questions []string
+	shown     []string // names of the evidence shown
+	unlocked  func(key string) bool
+}
+
+// metBy reports whether an interrogation has done enough to crack the secret
+func (t *SecretTrigger) metBy(in interrogation) bool {
+	if len(t.Keywords) > 0 && !mentionsAny(in.questions, t.Keywords) {
+		return false
+	}
+	if len(t.Evidence) > 0 && !containsAnyFold(in.shown, t.Evidence) {
+		return false
+	}
+	if t.Questions > 0 && len(in.questions) < t.Questions {
+		return false
+	}
+	if t.After != "" && !in.unlocked(t.After) {
+		return false
+	}
+	return true
+}
+
+// mentionsAny reports whether any text says one of the keywords in whole
+// words, so "dress" isn't found in "address"
+func mentionsAny(texts, keywords []string) bool {
+	for _, text := range texts {
+		for _, keyword := range keywords {
+			if mentionsPhrase(text, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsAnyFold(list, wanted []string) bool {
+	for _, w := range wanted {
+		if containsFold(list, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// interrogation gathers what the detective has asked and shown the
+// character, along with the turn they are about to take
+func (c *Character) interrogation(question string, shown *Evidence) interrogation {
+	var in interrogation
+	for _, msg := range c.Conversation {
+		switch {
+		case msg.Role != llm.RoleUser:
+		case msg.Type == MessageEvidence:
+			name := msg.Evidence
+			if name == "" {
+				// saved before the name was kept with the turn
+				name, _, _ = strings.Cut(msg.Content, " - ")
+			}
+			in.shown = append(in.shown, name)
+		case msg.Type == "":
+			in.questions = append(in.questions, msg.question())
+		}
+	}
+
+	if shown != nil {
+		in.shown = append(in.shown, shown.Name)
+	} else {
+		in.questions = append(in.questions, question)
+	}
+	return in
+}
+
+// isUnlocked reports whether a secret, by its key, may be confessed
+func (e *Engine) isUnlocked(key string) bool {
+	return containsFold(e.unlocked, key)
+}
+
+// unlockSecrets frees a character to confess any secret the detective's next
+// turn cracks, before they reply to it. shown is the evidence being presented,
+// if that's what the turn is.
+func (e *Engine) unlockSecrets(char *Character, question string, shown *Evidence) {
+	in := char.interrogation(question, shown)
+	in.unlocked = e.isUnlocked
+
+	cracked := false
+
+	// one secret can be what cracks the next
+	for changed := true; changed; {
+		changed = false
+		for _, secret := range char.Secrets {
+			if secret.Unlock == nil || e.isUnlocked(secret.key()) || !secret.Unlock.metBy(in) {
+				continue
+			}
+
+			e.unlocked = append(e.unlocked, secret.key())
+			char.allowSecret(secret, e.murder)
+			e.logger.Debug(fmt.Sprintf("[engine] %s may now reveal: %s", char.Name, secret.Text))
+			changed = true
+			cracked = true
+		}
+	}
+
+	if cracked {
+		fmt.Printf("😰 %s looks rattled...\n", char.Name)
+	}
+}
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSecretTriggerMetBy(t *testing.T) {
+	unlocked := func(key string) bool { return key == "divorce" }
+
+	tests := []struct {
+		name    string
+		trigger SecretTrigger
+		in      interrogation
+		want    bool
+	}{
+		{name: "no conditions", trigger: SecretTrigger{}, want: true},
+		{name: "keyword asked", trigger: SecretTrigger{Keywords: []string{"Library"}}, in: interrogation{questions: []string{"Were you in the library?"}}, want: true},
+		{name: "keyword not asked", trigger: SecretTrigger{Keywords: []string{"library"}}, in: interrogation{questions: []string{"Where were you?"}}, want: false},
+		{name: "keyword inside another word", trigger: SecretTrigger{Keywords: []string{"dress"}}, in: interrogation{questions: []string{"What is your address?"}}, want: false},
+		{name: "keyword phrase", trigger: SecretTrigger{Keywords: []string{"raised voices"}}, in: interrogation{questions: []string{"Were voices raised?", "Who heard raised voices?"}}, want: true},
+		{name: "blank keyword", trigger: SecretTrigger{Keywords: []string{" "}}, in: interrogation{questions: []string{"Where were you?"}}, want: false},
+		{name: "evidence shown", trigger: SecretTrigger{Evidence: []string{"torn letter"}}, in: interrogation{shown: []string{"Torn Letter"}}, want: true},
+		{name: "evidence not shown", trigger: SecretTrigger{Evidence: []string{"Torn letter"}}, in: interrogation{shown: []string{"Pruning Shears"}}, want: false},
+		{name: "enough questions", trigger: SecretTrigger{Questions: 3}, in: interrogation{questions: []string{"one", "two", "three"}}, want: true},
+		{name: "showing evidence isn't a question", trigger: SecretTrigger{Questions: 3}, in: interrogation{questions: []string{"one", "two"}, shown: []string{"Torn letter"}}, want: false},
+		{name: "too few questions", trigger: SecretTrigger{Questions: 3}, in: interrogation{questions: []string{"one", "two"}}, want: false},
+		{name: "after unlocked", trigger: SecretTrigger{After: "divorce"}, want: true},
+		{name: "after still locked", trigger: SecretTrigger{After: "affair"}, want: false},
+		{name: "every condition", trigger: SecretTrigger{Keywords: []string{"library"}, Questions: 2}, in: interrogation{questions: []string{"Were you in the library?"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.in.unlocked = unlocked
+			if got := tt.trigger.metBy(tt.in); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterrogation(t *testing.T) {
+	m := testMurder()
+	char := &m.Characters[0]
+	char.addQuestion("Where were you?", m)
+	char.addEvidence(&Evidence{Name: "Letter - torn", Description: "Half a page"}, m)
+	char.addQuestion("Who wrote it?", m)
+	char.Conversation = append(char.Conversation, &Message{Role: "user", Type: MessageEvidence, Content: "Pruning Shears - Wiped clean"}) // an older save
+
+	in := char.interrogation("And now?", nil)
+	if want := []string{"Where were you?", "Who wrote it?", "And now?"}; !slices.Equal(in.questions, want) {
+		t.Errorf("questions %q, want %q", in.questions, want)
+	}
+	if want := []string{"Letter - torn", "Pruning Shears"}; !slices.Equal(in.shown, want) {
+		t.Errorf("shown %q, want %q", in.shown, want)
+	}
+}
+
+func TestSecretJSONRoundTrip(t *testing.T) {
+	data := `["Reads other people's letters",{"text":"Was in the library","id":"library","unlock":{"keywords":["library"],"after":"divorce"}}]`
+
+	var secrets []Secret
+	if err := json.Unmarshal([]byte(data), &secrets); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if secrets[0].Text != "Reads other people's letters" || secrets[0].Unlock != nil {
+		t.Errorf("plain secret read as %+v", secrets[0])
+	}
+	if s := secrets[1]; s.Text != "Was in the library" || s.ID != "library" || s.Unlock == nil || s.Unlock.After != "divorce" {
+		t.Errorf("secret with a trigger read as %+v", s)
+	}
+
+	out, err := json.Marshal(secrets)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(out) != data {
+		t.Errorf("wrote back\n%s\nwant\n%s", out, data)
+	}
+}
+
+func TestUnlockSecrets(t *testing.T) {
+	m := testMurder()
+	m.Characters[0].Secrets = []Secret{
+		{Text: "Forged the will", ID: "will", Unlock: &SecretTrigger{Keywords: []string{"forgery"}}},
+		{Text: "Burned the old will", Unlock: &SecretTrigger{After: "will"}},
+		{Text: "Hates the questions", Unlock: &SecretTrigger{Keywords: []string{"detective", "question", "follow"}}},
+	}
+	m.Characters[1].Secrets = []Secret{
+		{Text: "Saw Vera at the fireplace", Unlock: &SecretTrigger{After: "will"}},
+	}
+
+	e := testEngine(t, m)
+	client := &fakeLLM{}
+	e.llm = client
+	vera, percy := &e.murder.Characters[0], &e.murder.Characters[1]
+
+	// the introduction the model sees on each question isn't the detective's
+	e.processQuestion(vera, "Where were you?")
+	e.processQuestion(vera, "And after that? Forgetting something?")
+	if len(e.unlocked) != 0 {
+		t.Fatalf("unlocked %v before the forgery came up", e.unlocked)
+	}
+
+	// one secret cracks the next
+	e.processQuestion(vera, "Tell me about the forgery.")
+	if want := []string{"will", "Burned the old will"}; !slices.Equal(e.unlocked, want) {
+		t.Fatalf("unlocked %v, want %v", e.unlocked, want)
+	}
+	last := client.prompts[len(client.prompts)-1]
+	for _, secret := range []string{"Forged the will", "Burned the old will"} {
+		if !strings.Contains(last, "you may admit it when pressed, in your own way and as your personality dictates: "+secret) {
+			t.Errorf("Vera wasn't freed to confess %q:\n%s", secret, last)
+		}
+	}
+
+	// whoever the earlier secret belongs to
+	e.processQuestion(percy, "Anything to add?")
+	if !e.isUnlocked("Saw Vera at the fireplace") {
+		t.Errorf("Percy's secret stayed locked after Vera's, unlocked %v", e.unlocked)
+	}
+}
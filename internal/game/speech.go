@@ -0,0 +1,154 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"gofigure/internal/logger"
+	"gofigure/internal/tts"
+	"strings"
+	"sync"
+	"time"
+)
+
+// abbreviations that end in a full stop without ending the sentence
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "st": true,
+	"lt": true, "col": true, "capt": true, "rev": true, "prof": true,
+}
+
+// sentenceTimeout bounds how long one sentence may take to synthesise and play
+const sentenceTimeout = time.Minute
+
+type sentence struct {
+	text    string
+	emotion string
+}
+
+// speechQueue speaks a streamed reply one sentence at a time, so speech
+// begins before the model has finished generating the rest of the reply
+type speechQueue struct {
+	tts     tts.Tts
+	model   string
+	prosody map[string]tts.Prosody
+
+	pending   strings.Builder
+	emotion   string
+	sentences chan sentence
+	done      chan struct{}
+
+	// ctx is cancelled to silence the queue, including the sentence being spoken
+	ctx  context.Context
+	stop context.CancelFunc
+
+	closing sync.Once
+}
+
+func newSpeechQueue(t tts.Tts, model string, prosody map[string]tts.Prosody) *speechQueue {
+	ctx, stop := context.WithCancel(context.Background())
+	q := &speechQueue{
+		tts:       t,
+		model:     model,
+		prosody:   prosody,
+		sentences: make(chan sentence, 32),
+		done:      make(chan struct{}),
+		ctx:       ctx,
+		stop:      stop,
+	}
+
+	go q.run()
+	return q
+}
+
+func (q *speechQueue) run() {
+	defer close(q.done)
+
+	for s := range q.sentences {
+		if q.ctx.Err() != nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(q.ctx, sentenceTimeout)
+		err := q.tts.Speak(ctx, tts.Line{Text: s.text, Emotions: s.emotion, Model: q.model, Prosody: q.prosody})
+		cancel()
+
+		if err != nil && !errors.Is(err, context.Canceled) {
+			logger.New().WithError(err).Error("character has lost their voice")
+		}
+	}
+}
+
+// add queues newly generated text, handing every completed sentence to tts
+func (q *speechQueue) add(text, emotion string) {
+	if emotion != "" {
+		q.emotion = emotion
+	}
+	q.pending.WriteString(text)
+
+	buffered := q.pending.String()
+	end := lastSentenceEnd(buffered)
+	if end <= 0 {
+		return
+	}
+
+	q.say(buffered[:end])
+	q.pending.Reset()
+	q.pending.WriteString(buffered[end:])
+}
+
+// finish speaks whatever is left and waits for the queue to drain
+func (q *speechQueue) finish() {
+	q.end()
+	<-q.done
+	q.stop()
+}
+
+// end speaks whatever is left without waiting for it. Nothing more can be
+// added, but the queue can still be cancelled.
+func (q *speechQueue) end() {
+	q.say(q.pending.String())
+	q.pending.Reset()
+	q.closing.Do(func() { close(q.sentences) })
+}
+
+// cancel silences the queue, dropping anything not yet spoken along with the
+// unfinished sentence, and waits for it to stop
+func (q *speechQueue) cancel() {
+	q.pending.Reset()
+	q.stop()
+	q.closing.Do(func() { close(q.sentences) })
+	<-q.done
+}
+
+func (q *speechQueue) say(text string) {
+	if text = strings.TrimSpace(text); text != "" {
+		q.sentences <- sentence{text: text, emotion: q.emotion}
+	}
+}
+
+// lastSentenceEnd returns the index just after the last complete sentence in
+// s, or 0 if no sentence has been completed yet. A sentence is only complete
+// once the whitespace after its punctuation has arrived.
+func lastSentenceEnd(s string) int {
+	end := 0
+	for i := 0; i < len(s)-1; i++ {
+		if !strings.ContainsRune(".!?", rune(s[i])) {
+			continue
+		}
+		if s[i+1] != ' ' && s[i+1] != '\n' {
+			continue
+		}
+		if s[i] == '.' && isAbbreviation(s[:i]) {
+			continue
+		}
+		end = i + 1
+	}
+	return end
+}
+
+func isAbbreviation(before string) bool {
+	fields := strings.Fields(before)
+	if len(fields) == 0 {
+		return false
+	}
+	return abbreviations[strings.ToLower(fields[len(fields)-1])]
+}
@@ -0,0 +1,97 @@
+package game
+
+import (
+	"context"
+	"gofigure/internal/tts"
+	"sync"
+	"testing"
+)
+
+func TestLastSentenceEnd(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"I was in the parlour", 0},
+		{"I was in the parlour.", 0}, // the space after it hasn't arrived yet
+		{"I was in the parlour. ", 21},
+		{"I was in the parlour. Then", 21},
+		{"Was I? Yes! Then I left. ", 24},
+		{"Ask Dr. Finch", 0},
+		{"Ask Mrs. Finch. ", 15},
+		{"It was 3.30 exactly", 0},
+		{"Goodbye.\nNext", 8},
+	}
+
+	for _, tt := range tests {
+		if got := lastSentenceEnd(tt.text); got != tt.want {
+			t.Errorf("lastSentenceEnd(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+// speaker records what it is asked to say, blocking on each sentence until
+// it is released or cancelled
+type speaker struct {
+	mu      sync.Mutex
+	said    []string
+	last    tts.Line
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *speaker) Speak(ctx context.Context, line tts.Line) error {
+	s.mu.Lock()
+	s.said = append(s.said, line.Text)
+	s.last = line
+	s.mu.Unlock()
+
+	if s.release == nil {
+		return nil
+	}
+	s.started <- struct{}{}
+	select {
+	case <-s.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *speaker) Name() string { return "speaker" }
+
+func TestSpeechQueueSpeaksSentencesAsTheyComplete(t *testing.T) {
+	s := &speaker{}
+	prosody := map[string]tts.Prosody{"calm": {Rate: 0.8}}
+	q := newSpeechQueue(s, "en-GB-Wavenet-N", prosody)
+
+	for _, chunk := range []string{"I was in ", "the parlour. Mr. Finch ", "saw me", " there"} {
+		q.add(chunk, "calm")
+	}
+	q.finish()
+
+	want := []string{"I was in the parlour.", "Mr. Finch saw me there"}
+	if len(s.said) != len(want) || s.said[0] != want[0] || s.said[1] != want[1] {
+		t.Errorf("spoke %q, want %q", s.said, want)
+	}
+	if s.last.Model != "en-GB-Wavenet-N" || s.last.Emotions != "calm" || s.last.Prosody["calm"] != prosody["calm"] {
+		t.Errorf("spoke %+v, want the character's voice and prosody", s.last)
+	}
+}
+
+func TestSpeechQueueCancelDropsTheRest(t *testing.T) {
+	s := &speaker{started: make(chan struct{}), release: make(chan struct{})}
+	q := newSpeechQueue(s, "", nil)
+
+	q.add("First sentence. ", "calm")
+	<-s.started
+	q.add("Second sentence. Half a thi", "calm")
+
+	// cancelling interrupts the first sentence and never speaks the others
+	q.cancel()
+
+	if len(s.said) != 1 || s.said[0] != "First sentence." {
+		t.Errorf("spoke %q after cancel, want only the interrupted first sentence", s.said)
+	}
+}
@@ -0,0 +1,374 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gofigure/internal/tts"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is a single mistake found in a mystery file
+type Problem struct {
+	File     string
+	Line     int
+	Path     string
+	Message  string
+	Severity Severity
+}
+
+func (p Problem) String() string {
+	path := p.Path
+	if path == "" {
+		path = "(root)"
+	}
+	return fmt.Sprintf("%s:%d: %s: %s: %s", p.File, p.Line, p.Severity, path, p.Message)
+}
+
+// Validate checks the fields every mystery needs to be playable
+func (m *Murder) Validate() error {
+	var errs []error
+	for _, p := range m.Problems() {
+		if p.Severity == SeverityError {
+			errs = append(errs, fmt.Errorf("%s: %s", p.Path, p.Message))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Problems checks the mystery for missing fields and broken references between them
+func (m *Murder) Problems() []Problem {
+	var problems []Problem
+	add := func(severity Severity, path, format string, args ...any) {
+		problems = append(problems, Problem{Path: path, Message: fmt.Sprintf(format, args...), Severity: severity})
+	}
+
+	required := []struct {
+		field string
+		value string
+	}{
+		{"title", m.Title},
+		{"introduction", m.Intro},
+		{"victim", m.Victim},
+		{"killer", m.Killer},
+		{"weapon", m.Weapon},
+		{"location", m.Location},
+		{"motive", m.Motive},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			add(SeverityError, r.field, "is required")
+		}
+	}
+
+	if len(m.Characters) == 0 {
+		add(SeverityError, "characters", "at least one character is required")
+	}
+
+	seen := map[string]bool{}
+	for i, char := range m.Characters {
+		path := fmt.Sprintf("characters[%d]", i)
+
+		if strings.TrimSpace(char.Name) == "" {
+			add(SeverityError, path+".name", "is required")
+		} else if seen[strings.ToLower(char.Name)] {
+			add(SeverityError, path+".name", "duplicate character '%s'", char.Name)
+		}
+		seen[strings.ToLower(char.Name)] = true
+
+		if len(char.Knowledge) == 0 {
+			add(SeverityError, path+".knowledge", "%s knows nothing about the case", char.Name)
+		}
+		for j, k := range char.Knowledge {
+			if strings.TrimSpace(k) == "" {
+				add(SeverityError, fmt.Sprintf("%s.knowledge[%d]", path, j), "is empty")
+			}
+		}
+
+		for j, secret := range char.Secrets {
+			if strings.TrimSpace(secret.Text) == "" {
+				add(SeverityError, fmt.Sprintf("%s.secrets[%d]", path, j), "is empty")
+			}
+		}
+
+		problems = append(problems, ttsProblems(path+".tts", char.TTS)...)
+	}
+	problems = append(problems, ttsProblems("narrator_tts", m.NarratorTTS)...)
+
+	seenEvidence := map[string]bool{}
+	for i, ev := range m.Evidence {
+		path := fmt.Sprintf("evidence[%d]", i)
+
+		if strings.TrimSpace(ev.Name) == "" {
+			add(SeverityError, path+".name", "is required")
+		} else if seenEvidence[strings.ToLower(ev.Name)] {
+			add(SeverityError, path+".name", "duplicate evidence '%s'", ev.Name)
+		}
+		seenEvidence[strings.ToLower(ev.Name)] = true
+
+		if strings.TrimSpace(ev.Description) == "" {
+			add(SeverityError, path+".description", "is required")
+		}
+
+		if ev.RevealedBy == nil {
+			continue
+		}
+		if len(ev.RevealedBy.Keywords) == 0 {
+			add(SeverityError, path+".revealed_by.keywords", "at least one keyword is required")
+		}
+		if c := ev.RevealedBy.Character; c != "" && len(m.Characters) > 0 && m.findCharacterByName(c) == nil {
+			add(SeverityError, path+".revealed_by.character", "'%s' is not one of the characters", c)
+		}
+	}
+
+	problems = append(problems, m.secretProblems()...)
+
+	seenLocations := map[string]bool{}
+	for _, loc := range m.Locations {
+		seenLocations[strings.ToLower(loc.Name)] = true
+	}
+	placed := map[string]string{}
+	seenRoom := map[string]bool{}
+	for i, loc := range m.Locations {
+		path := fmt.Sprintf("locations[%d]", i)
+
+		if strings.TrimSpace(loc.Name) == "" {
+			add(SeverityError, path+".name", "is required")
+		} else if seenRoom[strings.ToLower(loc.Name)] {
+			add(SeverityError, path+".name", "duplicate location '%s'", loc.Name)
+		}
+		seenRoom[strings.ToLower(loc.Name)] = true
+
+		if strings.TrimSpace(loc.Description) == "" {
+			add(SeverityError, path+".description", "is required")
+		}
+
+		for j, exit := range loc.Exits {
+			if !seenLocations[strings.ToLower(exit)] {
+				add(SeverityError, fmt.Sprintf("%s.exits[%d]", path, j), "'%s' is not one of the locations", exit)
+			} else if strings.EqualFold(exit, loc.Name) {
+				add(SeverityError, fmt.Sprintf("%s.exits[%d]", path, j), "'%s' leads to itself", exit)
+			}
+		}
+
+		for j, name := range loc.Characters {
+			char := m.findCharacterByName(name)
+			if char == nil {
+				add(SeverityError, fmt.Sprintf("%s.characters[%d]", path, j), "'%s' is not one of the characters", name)
+				continue
+			}
+			if room, ok := placed[char.Name]; ok && room != loc.Name {
+				add(SeverityError, fmt.Sprintf("%s.characters[%d]", path, j), "%s is already in the %s", char.Name, room)
+			}
+			placed[char.Name] = loc.Name
+		}
+	}
+
+	if len(m.Locations) > 0 {
+		if m.Location != "" && !seenLocations[strings.ToLower(m.Location)] {
+			add(SeverityError, "location", "the crime scene '%s' is not one of the locations, so it can't be visited", m.Location)
+		}
+		for _, room := range m.unreachable() {
+			add(SeverityWarning, "locations", "no way to walk from the %s to the %s", m.Locations[0].Name, room)
+		}
+		for i, ev := range m.Evidence {
+			if ev.Location != "" && !seenLocations[strings.ToLower(ev.Location)] {
+				add(SeverityWarning, fmt.Sprintf("evidence[%d].location", i), "'%s' is not one of the locations, so it can be searched from anywhere", ev.Location)
+			}
+		}
+	}
+
+	if m.Killer != "" && len(m.Characters) > 0 && m.findCharacterByName(m.Killer) == nil {
+		add(SeverityError, "killer", "'%s' is not one of the characters", m.Killer)
+	}
+
+	if m.Weapon != "" && !m.mentions(m.Weapon) {
+		add(SeverityWarning, "weapon", "'%s' is never mentioned by the introduction or any character, check for typos", m.Weapon)
+	}
+	if m.Location != "" && !m.mentions(m.Location) {
+		add(SeverityWarning, "location", "'%s' is never mentioned by the introduction or any character, check for typos", m.Location)
+	}
+
+	return problems
+}
+
+// secretProblems checks secret ids are unique and that triggers can be met
+func (m *Murder) secretProblems() []Problem {
+	var problems []Problem
+	add := func(path, format string, args ...any) {
+		problems = append(problems, Problem{Path: path, Message: fmt.Sprintf(format, args...), Severity: SeverityError})
+	}
+
+	ids := map[string]bool{}
+	for _, char := range m.Characters {
+		for _, secret := range char.Secrets {
+			if secret.ID != "" {
+				ids[strings.ToLower(secret.ID)] = true
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, char := range m.Characters {
+		for j, secret := range char.Secrets {
+			path := fmt.Sprintf("characters[%d].secrets[%d]", i, j)
+
+			if id := strings.ToLower(secret.ID); id != "" {
+				if seen[id] {
+					add(path+".id", "duplicate secret id '%s'", secret.ID)
+				}
+				seen[id] = true
+			}
+
+			t := secret.Unlock
+			if t == nil {
+				continue
+			}
+			if len(t.Keywords) == 0 && len(t.Evidence) == 0 && t.Questions == 0 && t.After == "" {
+				add(path+".unlock", "needs at least one condition")
+			}
+			for k, name := range t.Evidence {
+				if !slices.ContainsFunc(m.Evidence, func(ev Evidence) bool { return strings.EqualFold(ev.Name, name) }) {
+					add(fmt.Sprintf("%s.unlock.evidence[%d]", path, k), "'%s' is not one of the evidence", name)
+				}
+			}
+			if t.After != "" && !ids[strings.ToLower(t.After)] {
+				add(path+".unlock.after", "no secret has the id '%s'", t.After)
+			} else if t.After != "" && strings.EqualFold(t.After, secret.ID) {
+				add(path+".unlock.after", "a secret can't be unlocked after itself")
+			}
+		}
+	}
+	return problems
+}
+
+func ttsProblems(path string, options []TTS) []Problem {
+	var problems []Problem
+	for i, option := range options {
+		p := fmt.Sprintf("%s[%d].engine", path, i)
+		switch {
+		case strings.TrimSpace(option.Engine) == "":
+			problems = append(problems, Problem{Path: p, Message: "engine name is required", Severity: SeverityError})
+		case !containsFold(tts.Engines(), option.Engine):
+			problems = append(problems, Problem{Path: p, Message: fmt.Sprintf("unknown engine '%s' (known: %s)", option.Engine, strings.Join(tts.Engines(), ", ")), Severity: SeverityWarning})
+		}
+	}
+	return problems
+}
+
+// findCharacterByName matches a name exactly, or by whole words of a longer
+// character name such as "Mr. Graves" for "Mr. Graves the Butler"
+func (m *Murder) findCharacterByName(name string) *Character {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i := range m.Characters {
+		if strings.ToLower(m.Characters[i].Name) == name {
+			return &m.Characters[i]
+		}
+	}
+	for i := range m.Characters {
+		if matchesName(m.Characters[i].Name, name, false) {
+			return &m.Characters[i]
+		}
+	}
+	return nil
+}
+
+// mentions reports whether any significant word of s appears in the
+// introduction or in what the characters know
+func (m *Murder) mentions(s string) bool {
+	var text strings.Builder
+	text.WriteString(m.Intro)
+	for _, char := range m.Characters {
+		for _, k := range char.Knowledge {
+			text.WriteString(" " + k)
+		}
+		for _, secret := range char.Secrets {
+			text.WriteString(" " + secret.Text)
+		}
+	}
+	corpus := strings.ToLower(text.String())
+
+	for _, word := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !(r >= 'a' && r <= 'z')
+	}) {
+		if len(word) > 3 && strings.Contains(corpus, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateMysteryFile reports every problem in a mystery file: JSON syntax,
+// schema violations and broken references, each located by line and field path
+func ValidateMysteryFile(filename string) []Problem {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return []Problem{{File: filename, Message: err.Error(), Severity: SeverityError}}
+	}
+
+	problems := validateMystery(data)
+	for i := range problems {
+		problems[i].File = filename
+	}
+	return problems
+}
+
+// validateMystery runs every check of ValidateMysteryFile over a mystery
+// that is not, or not yet, in a file
+func validateMystery(data []byte) []Problem {
+	var syntaxErr *json.SyntaxError
+	if err := json.Unmarshal(data, new(any)); errors.As(err, &syntaxErr) {
+		line := strings.Count(string(data[:syntaxErr.Offset]), "\n") + 1
+		return []Problem{{Line: line, Message: syntaxErr.Error(), Severity: SeverityError}}
+	} else if err != nil {
+		return []Problem{{Line: 1, Message: err.Error(), Severity: SeverityError}}
+	}
+
+	problems, err := validateSchema(data)
+	if err != nil {
+		return []Problem{{Line: 1, Message: err.Error(), Severity: SeverityError}}
+	}
+
+	var m Murder
+	// type mismatches are already located by the schema check
+	if err := json.Unmarshal(data, &m); err != nil && len(problems) == 0 {
+		problems = append(problems, Problem{Message: fmt.Sprintf("failed to decode mystery JSON: %s", err), Severity: SeverityError})
+	}
+	problems = dedupeProblems(append(problems, m.Problems()...))
+
+	lines := indexLines(data)
+	for i := range problems {
+		problems[i].Line = lineFor(lines, problems[i].Path)
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Line < problems[j].Line
+	})
+	return problems
+}
+
+// dedupeProblems drops problems reported by both the schema and the reference checks
+func dedupeProblems(problems []Problem) []Problem {
+	seen := map[string]bool{}
+	var unique []Problem
+	for _, p := range problems {
+		if seen[p.Path] && p.Severity == SeverityError {
+			continue
+		}
+		if p.Severity == SeverityError {
+			seen[p.Path] = true
+		}
+		unique = append(unique, p)
+	}
+	return unique
+}
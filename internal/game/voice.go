@@ -0,0 +1,145 @@
+package game
+
+import (
+	"fmt"
+	"gofigure/internal/logger"
+	"gofigure/internal/sst"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// cleanTranscript drops the full stops recognizers add, which would otherwise
+// end up in character names
+func cleanTranscript(s string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(s, ".", " ")), " ")
+}
+
+// utterance combines a session's final results into one, as unsure as its
+// least confident part. Alternatives are only kept for a single result,
+// which covers most spoken commands.
+func utterance(finals []sst.Result) sst.Result {
+	heard := sst.Result{IsFinal: true}
+
+	var texts []string
+	for _, r := range finals {
+		texts = append(texts, r.Transcript)
+		if r.Confidence > 0 && (heard.Confidence == 0 || r.Confidence < heard.Confidence) {
+			heard.Confidence = r.Confidence
+		}
+	}
+	heard.Transcript = cleanTranscript(strings.Join(texts, " "))
+
+	if len(finals) == 1 {
+		for _, alt := range finals[0].Alternatives {
+			heard.Alternatives = append(heard.Alternatives, cleanTranscript(alt))
+		}
+	}
+
+	return heard
+}
+
+// chooseTranscript settles on what the detective said. An alternative that
+// names a command or a character beats a likelier reading that doesn't, and
+// a low confidence transcript is checked with the detective first.
+func (e *Engine) chooseTranscript(heard sst.Result) string {
+	var candidates []string
+	for _, c := range append([]string{heard.Transcript}, heard.Alternatives...) {
+		c = strings.TrimSpace(strings.ToLower(c))
+		if c != "" && !slices.Contains(candidates, c) {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	best := 0
+	for i, c := range candidates {
+		if e.transcriptScore(c) > e.transcriptScore(candidates[best]) {
+			best = i
+		}
+	}
+	if best > 0 {
+		logger.New().Debug(fmt.Sprintf("[engine] preferring '%s' over '%s'", candidates[best], candidates[0]))
+		preferred := candidates[best]
+		candidates = append([]string{preferred}, slices.Delete(candidates, best, best+1)...)
+	}
+
+	if heard.Confidence == 0 || float64(heard.Confidence) >= e.config.Sst.MinConfidence {
+		return candidates[0]
+	}
+	logger.New().Debug(fmt.Sprintf("[engine] low confidence transcript (%.2f)", heard.Confidence))
+
+	// audio replayed from stdin leaves nothing there to answer with
+	if slices.Contains(e.config.Sst.AudioInput, "-") {
+		return candidates[0]
+	}
+	return e.confirmTranscript(candidates)
+}
+
+// transcriptScore is how much of a transcript the game recognises: a command
+// word to start it and a character's name within it
+func (e *Engine) transcriptScore(transcript string) int {
+	words := strings.Fields(transcript)
+	for i, w := range words {
+		words[i] = strings.Trim(w, ",?!'\"")
+	}
+	if len(words) == 0 {
+		return 0
+	}
+
+	score := 0
+	if slices.Contains(commandWords, words[0]) {
+		score++
+	}
+
+	for _, char := range e.murder.Characters {
+		for _, name := range strings.Fields(strings.ToLower(parenthetical.ReplaceAllString(char.Name, ""))) {
+			if name = strings.Trim(name, ".,'\""); len(name) > 2 && !abbreviations[name] && slices.Contains(words, name) {
+				return score + 1
+			}
+		}
+	}
+
+	return score
+}
+
+// confirmTranscript asks the detective whether they were heard correctly,
+// returning "" if they want to ask again
+func (e *Engine) confirmTranscript(candidates []string) string {
+	if len(candidates) == 1 {
+		fmt.Printf("🤔 Did you say \"%s\"? [Y/n] ", candidates[0])
+		answer := e.readAnswer()
+
+		if strings.HasPrefix(answer, "n") {
+			fmt.Println("Let's try that again.")
+			return ""
+		}
+		return candidates[0]
+	}
+
+	fmt.Println("🤔 Did you mean...")
+	for i, c := range candidates {
+		fmt.Printf("  %d. %s\n", i+1, c)
+	}
+	fmt.Print("Press ENTER for 1, pick a number, or 'n' to try again: ")
+
+	answer := e.readAnswer()
+	if strings.HasPrefix(answer, "n") {
+		fmt.Println("Let's try that again.")
+		return ""
+	}
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(candidates) {
+		return candidates[n-1]
+	}
+	return candidates[0]
+}
+
+// readAnswer reads one typed line, or "" if stdin has closed
+func (e *Engine) readAnswer() string {
+	if !e.scanner.Scan() {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(e.scanner.Text()))
+}
@@ -0,0 +1,156 @@
+package game
+
+import (
+	"bufio"
+	"gofigure/internal/sst"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestUtterance(t *testing.T) {
+	tests := []struct {
+		name   string
+		finals []sst.Result
+		want   sst.Result
+	}{
+		{name: "nothing heard", want: sst.Result{IsFinal: true}},
+		{
+			name:   "one result keeps its alternatives",
+			finals: []sst.Result{{Transcript: "interview Percy.", Confidence: 0.7, Alternatives: []string{"interview pursey.", "in a view Percy"}}},
+			want:   sst.Result{Transcript: "interview Percy", IsFinal: true, Confidence: 0.7, Alternatives: []string{"interview pursey", "in a view Percy"}},
+		},
+		{
+			name:   "joined as unsure as the least confident",
+			finals: []sst.Result{{Transcript: "Where were you.", Confidence: 0.9, Alternatives: []string{"wear"}}, {Transcript: "at midnight", Confidence: 0.6}},
+			want:   sst.Result{Transcript: "Where were you at midnight", IsFinal: true, Confidence: 0.6},
+		},
+		{
+			name:   "unknown confidence is ignored",
+			finals: []sst.Result{{Transcript: "accuse Vera"}, {Transcript: "with the shears", Confidence: 0.8}},
+			want:   sst.Result{Transcript: "accuse Vera with the shears", IsFinal: true, Confidence: 0.8},
+		},
+		{
+			name:   "titles lose their full stop",
+			finals: []sst.Result{{Transcript: "interview Mrs. Oduya."}},
+			want:   sst.Result{Transcript: "interview Mrs Oduya", IsFinal: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := utterance(tt.finals)
+			if got.Transcript != tt.want.Transcript || got.IsFinal != tt.want.IsFinal || got.Confidence != tt.want.Confidence || !slices.Equal(got.Alternatives, tt.want.Alternatives) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscriptScore(t *testing.T) {
+	e := testEngine(t, testMurder())
+
+	tests := map[string]int{
+		"":                           0,
+		"where were you":             0,
+		"interview somebody":         1,
+		"what did percy see":         1,
+		"interview percy":            2,
+		"interview percy, please":    2,
+		"examine the holt family":    2, // surnames count too
+		"accuse mrs with the shears": 1, // titles aren't names
+		"ask vera's alibi":           0, // possessives don't match
+	}
+
+	for transcript, want := range tests {
+		if got := e.transcriptScore(transcript); got != want {
+			t.Errorf("transcriptScore(%q) = %d, want %d", transcript, got, want)
+		}
+	}
+}
+
+// readCounter counts how often the game reads what the detective typed
+type readCounter struct {
+	r     io.Reader
+	reads int
+}
+
+func (c *readCounter) Read(p []byte) (int, error) {
+	c.reads++
+	return c.r.Read(p)
+}
+
+func TestChooseTranscript(t *testing.T) {
+	tests := []struct {
+		name  string
+		heard sst.Result
+		typed string // the detective's answer when asked to confirm
+		audio []string
+		want  string
+		asked bool
+	}{
+		{name: "nothing heard", heard: sst.Result{}, want: ""},
+		{name: "confident", heard: sst.Result{Transcript: "Where were you", Confidence: 0.9}, want: "where were you"},
+		{name: "no confidence given", heard: sst.Result{Transcript: "where were you"}, want: "where were you"},
+		{
+			name:  "alternative naming a character wins",
+			heard: sst.Result{Transcript: "interview pursey", Confidence: 0.8, Alternatives: []string{"interview percy"}},
+			want:  "interview percy",
+		},
+		{
+			name:  "duplicates are dropped before asking",
+			heard: sst.Result{Transcript: "Where were you", Confidence: 0.3, Alternatives: []string{"where were you"}},
+			typed: "\n",
+			want:  "where were you",
+			asked: true,
+		},
+		{name: "unsure, confirmed", heard: sst.Result{Transcript: "look", Confidence: 0.3}, typed: "y\n", want: "look", asked: true},
+		{name: "unsure, rejected", heard: sst.Result{Transcript: "look", Confidence: 0.3}, typed: "No\n", want: "", asked: true},
+		{
+			name:  "unsure, picked",
+			heard: sst.Result{Transcript: "interview percy", Confidence: 0.3, Alternatives: []string{"interview pursey"}},
+			typed: "2\n",
+			want:  "interview pursey",
+			asked: true,
+		},
+		{
+			name:  "unsure, out of range",
+			heard: sst.Result{Transcript: "interview percy", Confidence: 0.3, Alternatives: []string{"interview pursey"}},
+			typed: "7\n",
+			want:  "interview percy",
+			asked: true,
+		},
+		{
+			name:  "unsure, replaying stdin",
+			heard: sst.Result{Transcript: "interview percy", Confidence: 0.3, Alternatives: []string{"interview pursey"}},
+			typed: "\x01\x02\x03\n",
+			audio: []string{"-"},
+			want:  "interview percy",
+		},
+		{
+			name:  "unsure, stdin closed",
+			heard: sst.Result{Transcript: "interview percy", Confidence: 0.3},
+			want:  "interview percy",
+			asked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := testEngine(t, testMurder())
+			e.config.Sst.MinConfidence = 0.6
+			e.config.Sst.AudioInput = tt.audio
+
+			stdin := &readCounter{r: strings.NewReader(tt.typed)}
+			e.scanner = bufio.NewScanner(stdin)
+
+			if got := e.chooseTranscript(tt.heard); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if asked := stdin.reads > 0; asked != tt.asked {
+				t.Errorf("asked the detective = %v, want %v", asked, tt.asked)
+			}
+		})
+	}
+}
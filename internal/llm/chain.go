@@ -0,0 +1,203 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gofigure/internal/logger"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how hard the chain tries one provider before moving on
+type RetryPolicy struct {
+	Retries          int           // extra attempts after a retryable failure
+	Backoff          time.Duration // wait before the first retry, doubled after each one
+	FailureThreshold int           // consecutive failures that open the circuit
+	Cooldown         time.Duration // how long an open circuit skips the provider
+}
+
+// Chain is an LLM that tries each of its providers in order. Transient
+// failures are retried with backoff, and a provider that keeps failing is
+// skipped until its cooldown has passed.
+type Chain struct {
+	links  []*link
+	logger *logger.Log
+
+	// now and after tell the time for backoff and cooldowns
+	now   func() time.Time
+	after func(time.Duration) <-chan time.Time
+}
+
+type link struct {
+	name   string
+	client LLM
+	policy RetryPolicy
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+func NewChain() *Chain {
+	return &Chain{logger: logger.New(), now: time.Now, after: time.After}
+}
+
+// Add appends a provider to the end of the chain
+func (c *Chain) Add(name string, client LLM, policy RetryPolicy) *Chain {
+	c.links = append(c.links, &link{name: name, client: client, policy: policy})
+	return c
+}
+
+// Len returns the number of providers in the chain
+func (c *Chain) Len() int {
+	return len(c.links)
+}
+
+func (c *Chain) GenerateResponse(ctx context.Context, prompt string) (string, error) {
+	return c.do(ctx, func(client LLM) (string, error) {
+		return client.GenerateResponse(ctx, prompt)
+	}, nil)
+}
+
+func (c *Chain) Chat(ctx context.Context, messages []Message, opts Options) (string, error) {
+	return c.do(ctx, func(client LLM) (string, error) {
+		return client.Chat(ctx, messages, opts)
+	}, nil)
+}
+
+// ChatStream only falls back while nothing has been streamed; once part of a
+// reply has reached onChunk, starting again elsewhere would repeat it
+func (c *Chain) ChatStream(ctx context.Context, messages []Message, opts Options, onChunk func(string)) (string, error) {
+	started := false
+	return c.do(ctx, func(client LLM) (string, error) {
+		return client.ChatStream(ctx, messages, opts, func(chunk string) {
+			started = true
+			if onChunk != nil {
+				onChunk(chunk)
+			}
+		})
+	}, func() bool { return started })
+}
+
+// IsModelAvailable succeeds if any provider is ready. Providers that are not
+// have their circuit opened so the game does not wait on them.
+func (c *Chain) IsModelAvailable(ctx context.Context) error {
+	var errs []error
+	for _, l := range c.links {
+		if err := l.client.IsModelAvailable(ctx); err != nil {
+			c.logger.Warn(fmt.Sprintf("llm provider %s is not available: %v", l.name, err))
+			errs = append(errs, fmt.Errorf("%s: %w", l.name, err))
+			l.trip(c.now())
+			continue
+		}
+		c.logger.Debug(fmt.Sprintf("llm provider %s is available", l.name))
+	}
+
+	if len(errs) == len(c.links) {
+		return fmt.Errorf("no llm provider available: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// do runs call against each provider in turn until one succeeds. started
+// reports whether output has already been passed on, after which the chain
+// stops falling back.
+func (c *Chain) do(ctx context.Context, call func(LLM) (string, error), started func() bool) (string, error) {
+	if started == nil {
+		started = func() bool { return false }
+	}
+
+	var errs []error
+	for _, l := range c.links {
+		if !l.allow(c.now()) {
+			c.logger.Debug(fmt.Sprintf("[llm] skipping %s, circuit open", l.name))
+			errs = append(errs, fmt.Errorf("%s: circuit open", l.name))
+			continue
+		}
+
+		resp, err := c.try(ctx, l, call, started)
+		if err == nil {
+			return resp, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", l.name, err))
+
+		if ctx.Err() != nil || started() {
+			break
+		}
+		c.logger.Warn(fmt.Sprintf("llm provider %s failed [%s], falling back", l.name, Classify(err)))
+	}
+
+	return "", fmt.Errorf("all llm providers failed: %w", errors.Join(errs...))
+}
+
+// try calls a single provider, retrying failures that might be transient
+func (c *Chain) try(ctx context.Context, l *link, call func(LLM) (string, error), started func() bool) (string, error) {
+	backoff := l.policy.Backoff
+
+	for attempt := 0; ; attempt++ {
+		resp, err := call(l.client)
+		if err == nil {
+			l.succeeded()
+			return resp, nil
+		}
+
+		// the caller gave up, which says nothing about the provider
+		if ctx.Err() != nil {
+			return "", err
+		}
+
+		kind := Classify(err)
+		if attempt >= l.policy.Retries || !kind.Retryable() || started() {
+			l.failed(kind, c.now())
+			return "", err
+		}
+
+		c.logger.Debug(fmt.Sprintf("[llm] %s failed [%s], retrying in %s", l.name, kind, backoff))
+		select {
+		case <-c.after(backoff):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// allow reports whether the circuit is closed, or has cooled down enough to try again
+func (l *link) allow(now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.failures < l.policy.FailureThreshold || now.After(l.openUntil)
+}
+
+func (l *link) succeeded() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures = 0
+}
+
+// failed counts a failure, opening the circuit once the threshold is reached.
+// Auth failures will not fix themselves, so they open it straight away.
+func (l *link) failed(kind ErrorKind, now time.Time) {
+	if kind == ErrorAuth {
+		l.trip(now)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.failures++
+	if l.failures >= l.policy.FailureThreshold {
+		l.openUntil = now.Add(l.policy.Cooldown)
+	}
+}
+
+// trip opens the circuit immediately
+func (l *link) trip(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.failures = max(l.failures+1, l.policy.FailureThreshold)
+	l.openUntil = now.Add(l.policy.Cooldown)
+}
@@ -0,0 +1,278 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scripted is a provider that fails with each of its errors in turn, then answers
+type scripted struct {
+	name  string
+	errs  []error
+	calls int
+
+	// streamed is sent to onChunk before a streamed call fails
+	streamed string
+}
+
+func (s *scripted) next() (string, error) {
+	s.calls++
+	if s.calls <= len(s.errs) && s.errs[s.calls-1] != nil {
+		return "", s.errs[s.calls-1]
+	}
+	return s.name + " answered", nil
+}
+
+func (s *scripted) GenerateResponse(context.Context, string) (string, error) {
+	return s.next()
+}
+
+func (s *scripted) Chat(context.Context, []Message, Options) (string, error) {
+	return s.next()
+}
+
+func (s *scripted) ChatStream(_ context.Context, _ []Message, _ Options, onChunk func(string)) (string, error) {
+	resp, err := s.next()
+	if err != nil && s.streamed != "" {
+		onChunk(s.streamed)
+	}
+	if err == nil {
+		onChunk(resp)
+	}
+	return resp, err
+}
+
+func (s *scripted) IsModelAvailable(context.Context) error {
+	_, err := s.next()
+	return err
+}
+
+// always repeats err forever
+func always(err error) []error {
+	errs := make([]error, 100)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
+
+// fakeClock only moves when the chain waits, or the test moves it
+type fakeClock struct {
+	t     time.Time
+	waits []time.Duration
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) after(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	c.t = c.t.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.t
+	return ch
+}
+
+func testChain(clock *fakeClock, policy RetryPolicy, providers ...*scripted) *Chain {
+	c := NewChain()
+	c.now, c.after = clock.now, clock.after
+	for _, p := range providers {
+		c.Add(p.name, p, policy)
+	}
+	return c
+}
+
+var (
+	errServer    = &StatusError{Provider: "test", StatusCode: 503}
+	errRateLimit = &StatusError{Provider: "test", StatusCode: 429}
+	errRequest   = &StatusError{Provider: "test", StatusCode: 400}
+	errAuth      = &StatusError{Provider: "test", StatusCode: 401}
+)
+
+func TestChainRetries(t *testing.T) {
+	policy := RetryPolicy{Retries: 2, Backoff: 100 * time.Millisecond, FailureThreshold: 10}
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantErr   bool
+		wantCalls int
+		wantWaits []time.Duration
+	}{
+		{name: "first time", wantCalls: 1},
+		{name: "server error", errs: []error{errServer}, wantCalls: 2, wantWaits: []time.Duration{100 * time.Millisecond}},
+		{name: "backoff doubles", errs: []error{context.DeadlineExceeded, errRateLimit}, wantCalls: 3, wantWaits: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}},
+		{name: "out of retries", errs: always(errServer), wantErr: true, wantCalls: 3, wantWaits: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}},
+		{name: "bad request is not retried", errs: []error{errRequest}, wantErr: true, wantCalls: 1},
+		{name: "auth is not retried", errs: []error{errAuth}, wantErr: true, wantCalls: 1},
+		{name: "unknown errors are not retried", errs: []error{errors.New("boom")}, wantErr: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &fakeClock{}
+			p := &scripted{name: "primary", errs: tt.errs}
+
+			resp, err := testChain(clock, policy, p).Chat(context.Background(), nil, Options{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got (%q, %v), want error %v", resp, err, tt.wantErr)
+			}
+			if p.calls != tt.wantCalls {
+				t.Errorf("provider called %d times, want %d", p.calls, tt.wantCalls)
+			}
+			if fmt.Sprint(clock.waits) != fmt.Sprint(tt.wantWaits) {
+				t.Errorf("waited %v, want %v", clock.waits, tt.wantWaits)
+			}
+		})
+	}
+}
+
+func TestChainFallsBack(t *testing.T) {
+	clock := &fakeClock{}
+	primary := &scripted{name: "primary", errs: always(errServer)}
+	secondary := &scripted{name: "secondary"}
+
+	resp, err := testChain(clock, RetryPolicy{Retries: 1, FailureThreshold: 10}, primary, secondary).Chat(context.Background(), nil, Options{})
+	if err != nil || resp != "secondary answered" {
+		t.Fatalf("got (%q, %v), want the secondary's answer", resp, err)
+	}
+	if primary.calls != 2 {
+		t.Errorf("primary called %d times, want 2", primary.calls)
+	}
+}
+
+func TestChainReportsEveryFailure(t *testing.T) {
+	primary := &scripted{name: "primary", errs: always(errAuth)}
+	secondary := &scripted{name: "secondary", errs: always(errServer)}
+
+	_, err := testChain(&fakeClock{}, RetryPolicy{FailureThreshold: 10}, primary, secondary).Chat(context.Background(), nil, Options{})
+	if err == nil || !strings.Contains(err.Error(), "primary") || !strings.Contains(err.Error(), "secondary") {
+		t.Fatalf("got %v, want both failures", err)
+	}
+	if Classify(err) != ErrorAuth {
+		t.Errorf("got %s, want the first failure to classify the error", Classify(err))
+	}
+}
+
+func TestChainCircuitBreaker(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	primary := &scripted{name: "primary", errs: always(errServer)}
+	secondary := &scripted{name: "secondary"}
+	chain := testChain(clock, RetryPolicy{FailureThreshold: 2, Cooldown: 30 * time.Second}, primary, secondary)
+
+	steps := []struct {
+		advance     time.Duration
+		wantPrimary int // calls made to the primary so far
+	}{
+		{0, 1},
+		{0, 2},                // threshold reached, circuit opens
+		{0, 2},                // skipped
+		{29 * time.Second, 2}, // still cooling down
+		{2 * time.Second, 3},  // cooled down, tried again and fails
+		{0, 3},                // open again straight away
+	}
+
+	for i, step := range steps {
+		clock.t = clock.t.Add(step.advance)
+		resp, err := chain.Chat(context.Background(), nil, Options{})
+		if err != nil || resp != "secondary answered" {
+			t.Fatalf("call %d: got (%q, %v)", i+1, resp, err)
+		}
+		if primary.calls != step.wantPrimary {
+			t.Errorf("call %d: primary called %d times, want %d", i+1, primary.calls, step.wantPrimary)
+		}
+	}
+}
+
+func TestChainSuccessClosesTheCircuit(t *testing.T) {
+	primary := &scripted{name: "primary", errs: []error{errServer, nil, errServer}}
+	secondary := &scripted{name: "secondary"}
+	chain := testChain(&fakeClock{}, RetryPolicy{FailureThreshold: 2, Cooldown: time.Minute}, primary, secondary)
+
+	// fail, succeed, fail: never two failures in a row
+	for i := 0; i < 4; i++ {
+		chain.Chat(context.Background(), nil, Options{})
+	}
+	if primary.calls != 4 {
+		t.Errorf("primary called %d times, want 4 with its circuit closed", primary.calls)
+	}
+}
+
+func TestChainAuthFailureOpensTheCircuit(t *testing.T) {
+	clock := &fakeClock{}
+	primary := &scripted{name: "primary", errs: []error{errAuth}}
+	secondary := &scripted{name: "secondary"}
+	chain := testChain(clock, RetryPolicy{FailureThreshold: 5, Cooldown: time.Minute}, primary, secondary)
+
+	chain.Chat(context.Background(), nil, Options{})
+	chain.Chat(context.Background(), nil, Options{})
+	if primary.calls != 1 {
+		t.Errorf("primary called %d times after an auth failure, want 1", primary.calls)
+	}
+
+	clock.t = clock.t.Add(2 * time.Minute)
+	if resp, _ := chain.Chat(context.Background(), nil, Options{}); resp != "primary answered" {
+		t.Errorf("got %q after the cooldown, want the primary back", resp)
+	}
+}
+
+func TestChainStreamDoesNotFallBackOnceStarted(t *testing.T) {
+	primary := &scripted{name: "primary", errs: always(errServer), streamed: "I was"}
+	secondary := &scripted{name: "secondary"}
+	chain := testChain(&fakeClock{}, RetryPolicy{Retries: 3, FailureThreshold: 10}, primary, secondary)
+
+	var got strings.Builder
+	_, err := chain.ChatStream(context.Background(), nil, Options{}, func(chunk string) { got.WriteString(chunk) })
+	if err == nil {
+		t.Fatal("got no error for a broken stream")
+	}
+	if primary.calls != 1 || secondary.calls != 0 {
+		t.Errorf("primary called %d times and secondary %d, want only the one attempt", primary.calls, secondary.calls)
+	}
+	if got.String() != "I was" {
+		t.Errorf("streamed %q, want only what the primary sent", got.String())
+	}
+}
+
+func TestChainStopsWhenTheCallerGivesUp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	primary := &scripted{name: "primary", errs: always(context.Canceled)}
+	secondary := &scripted{name: "secondary"}
+	chain := testChain(&fakeClock{}, RetryPolicy{Retries: 3, FailureThreshold: 1, Cooldown: time.Minute}, primary, secondary)
+
+	if _, err := chain.Chat(ctx, nil, Options{}); err == nil {
+		t.Fatal("got no error")
+	}
+	if primary.calls != 1 || secondary.calls != 0 {
+		t.Errorf("primary called %d times and secondary %d, want the chain to stop", primary.calls, secondary.calls)
+	}
+
+	// the primary wasn't at fault, so its circuit stays closed
+	if _, err := chain.Chat(context.Background(), nil, Options{}); primary.calls != 2 {
+		t.Errorf("primary skipped after a cancelled call: %v", err)
+	}
+}
+
+func TestChainIsModelAvailable(t *testing.T) {
+	clock := &fakeClock{}
+	primary := &scripted{name: "primary", errs: []error{errors.New("model not found")}}
+	secondary := &scripted{name: "secondary"}
+	chain := testChain(clock, RetryPolicy{FailureThreshold: 3, Cooldown: time.Minute}, primary, secondary)
+
+	if err := chain.IsModelAvailable(context.Background()); err != nil {
+		t.Fatalf("got %v with one provider available", err)
+	}
+	if resp, _ := chain.Chat(context.Background(), nil, Options{}); resp != "secondary answered" {
+		t.Errorf("got %q, want the unavailable primary skipped", resp)
+	}
+
+	none := testChain(clock, RetryPolicy{}, &scripted{name: "only", errs: []error{errors.New("down")}})
+	if err := none.IsModelAvailable(context.Background()); err == nil {
+		t.Error("got no error with no provider available")
+	}
+}
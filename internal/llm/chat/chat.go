@@ -0,0 +1,56 @@
+// Package chat holds the message types shared by the llm interface and its providers
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Roles understood by both ollama and openai compatible chat endpoints
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a single turn of a conversation with the model
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Options tune a single chat request. Zero values fall back to the provider defaults.
+type Options struct {
+	// Temperature is a pointer so a request can ask for exactly 0
+	Temperature *float64
+	MaxTokens   int
+
+	// JSON asks providers with a native JSON mode to only return a JSON object
+	JSON bool
+	// Schema further constrains the JSON reply on providers configured for
+	// structured output; others fall back to plain JSON mode
+	Schema json.RawMessage
+}
+
+// TemperatureOr is the requested temperature, or def if none was set
+func (o Options) TemperatureOr(def float64) float64 {
+	if o.Temperature == nil {
+		return def
+	}
+	return *o.Temperature
+}
+
+// StatusError is returned by providers when the server answers with an
+// unsuccessful HTTP status, so callers can tell rate limits from auth failures
+type StatusError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s API error: status %d", e.Provider, e.StatusCode)
+	}
+	return fmt.Sprintf("%s API error: status %d: %s", e.Provider, e.StatusCode, e.Message)
+}
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"gofigure/internal/llm/chat"
+	"net"
+	"net/http"
+)
+
+// StatusError lives in the chat package so providers can return it
+type StatusError = chat.StatusError
+
+// ErrorKind classifies a provider failure, deciding whether the call is
+// retried, handed to the next provider or abandoned
+type ErrorKind string
+
+const (
+	ErrorTimeout     ErrorKind = "timeout"
+	ErrorRateLimit   ErrorKind = "rate limit"
+	ErrorServer      ErrorKind = "server error"
+	ErrorUnavailable ErrorKind = "unavailable"
+	ErrorAuth        ErrorKind = "auth"
+	ErrorRequest     ErrorKind = "bad request"
+	ErrorCanceled    ErrorKind = "canceled"
+	ErrorUnknown     ErrorKind = "unknown"
+)
+
+// Classify works out what kind of failure err is
+func Classify(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return ErrorCanceled
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrorTimeout
+	}
+
+	var se *StatusError
+	if errors.As(err, &se) {
+		switch code := se.StatusCode; {
+		case code == http.StatusUnauthorized || code == http.StatusForbidden:
+			return ErrorAuth
+		case code == http.StatusTooManyRequests:
+			return ErrorRateLimit
+		case code == http.StatusRequestTimeout || code == http.StatusGatewayTimeout:
+			return ErrorTimeout
+		case code >= 500:
+			return ErrorServer
+		default:
+			return ErrorRequest
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrorTimeout
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ErrorUnavailable
+	}
+
+	return ErrorUnknown
+}
+
+// Retryable reports whether trying the same provider again might succeed
+func (k ErrorKind) Retryable() bool {
+	switch k {
+	case ErrorTimeout, ErrorRateLimit, ErrorServer:
+		return true
+	}
+	return false
+}
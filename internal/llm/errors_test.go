@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+// timeoutError is a net.Error that timed out
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassify(t *testing.T) {
+	status := func(code int) error { return &StatusError{Provider: "test", StatusCode: code} }
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{"nil", nil, ""},
+		{"canceled", context.Canceled, ErrorCanceled},
+		{"deadline", context.DeadlineExceeded, ErrorTimeout},
+		{"wrapped deadline", fmt.Errorf("chat failed: %w", context.DeadlineExceeded), ErrorTimeout},
+		{"401", status(401), ErrorAuth},
+		{"403", status(403), ErrorAuth},
+		{"429", status(429), ErrorRateLimit},
+		{"408", status(408), ErrorTimeout},
+		{"504", status(504), ErrorTimeout},
+		{"500", status(500), ErrorServer},
+		{"503", status(503), ErrorServer},
+		{"400", status(400), ErrorRequest},
+		{"404", status(404), ErrorRequest},
+		{"wrapped status", fmt.Errorf("openai: %w", status(429)), ErrorRateLimit},
+		{"joined", errors.Join(errors.New("other"), status(401)), ErrorAuth},
+		{"network timeout", &net.OpError{Op: "dial", Err: timeoutError{}}, ErrorTimeout},
+		{"connection refused", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, ErrorUnavailable},
+		{"unknown", errors.New("boom"), ErrorUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.err); got != tt.want {
+			t.Errorf("%s: Classify = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	retryable := map[ErrorKind]bool{
+		ErrorTimeout:     true,
+		ErrorRateLimit:   true,
+		ErrorServer:      true,
+		ErrorUnavailable: false,
+		ErrorAuth:        false,
+		ErrorRequest:     false,
+		ErrorCanceled:    false,
+		ErrorUnknown:     false,
+	}
+
+	for kind, want := range retryable {
+		if got := kind.Retryable(); got != want {
+			t.Errorf("%s: Retryable = %v, want %v", kind, got, want)
+		}
+	}
+}
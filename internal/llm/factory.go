@@ -2,10 +2,13 @@
 package llm
 
 import (
+	"errors"
 	"fmt"
 	"gofigure/config"
 	"gofigure/internal/llm/ollama"
 	"gofigure/internal/llm/openai"
+	"gofigure/internal/logger"
+	"time"
 )
 
 type Provider string
@@ -15,14 +18,87 @@ const (
 	ProviderOpenAI Provider = "openai"
 )
 
-// NewLLMClient creates a new LLM client based on the configuration
+// Retry policy defaults for links in the provider chain
+const (
+	defaultBackoff          = 500 * time.Millisecond
+	defaultFailureThreshold = 3
+	defaultCooldown         = 30 * time.Second
+)
+
+// NewLLMClient creates a new LLM client based on the configuration. When
+// llm.providers is set the client is a Chain falling back through them in order.
 func NewLLMClient(cfg *config.Config) (LLM, error) {
-	switch Provider(cfg.LLM.Provider) {
+	if len(cfg.LLM.Providers) == 0 {
+		return newProvider(cfg, cfg.LLM.Provider)
+	}
+
+	chain := NewChain()
+	for _, p := range cfg.LLM.Providers {
+		client, err := newProvider(cfg, p.Name)
+		if err != nil {
+			logger.New().Warn(fmt.Sprintf("skipping llm provider %s: %v", p.Name, err))
+			continue
+		}
+		chain.Add(p.Name, client, retryPolicy(p))
+	}
+
+	if chain.Len() == 0 {
+		return nil, errors.New("none of the configured llm providers could be created")
+	}
+	return chain, nil
+}
+
+func newProvider(cfg *config.Config, name string) (LLM, error) {
+	switch Provider(name) {
 	case ProviderOllama:
 		return ollama.NewClient(&cfg.Ollama)
 	case ProviderOpenAI:
 		return openai.NewClient(&cfg.OpenAI)
 	default:
-		return nil, fmt.Errorf("unsupported LLM provider: %s", cfg.LLM.Provider)
+		return nil, fmt.Errorf("unsupported LLM provider: %s", name)
+	}
+}
+
+func retryPolicy(p config.ProviderConfig) RetryPolicy {
+	policy := RetryPolicy{
+		Retries:          max(p.Retries, 0),
+		Backoff:          time.Duration(p.Backoff) * time.Millisecond,
+		FailureThreshold: p.FailureThreshold,
+		Cooldown:         time.Duration(p.Cooldown) * time.Second,
+	}
+
+	if policy.Backoff <= 0 {
+		policy.Backoff = defaultBackoff
+	}
+	if policy.FailureThreshold <= 0 {
+		policy.FailureThreshold = defaultFailureThreshold
+	}
+	if policy.Cooldown <= 0 {
+		policy.Cooldown = defaultCooldown
+	}
+	return policy
+}
+
+// Timeout is the longest a single call can take, allowing for every attempt
+// on every provider in the chain
+func Timeout(cfg *config.Config) time.Duration {
+	if len(cfg.LLM.Providers) == 0 {
+		return providerTimeout(cfg, cfg.LLM.Provider)
+	}
+
+	var total time.Duration
+	for _, p := range cfg.LLM.Providers {
+		policy := retryPolicy(p)
+		attempts := time.Duration(policy.Retries + 1)
+		backoff := policy.Backoff * (1<<policy.Retries - 1)
+		total += attempts*providerTimeout(cfg, p.Name) + backoff
+	}
+	return total
+}
+
+func providerTimeout(cfg *config.Config, name string) time.Duration {
+	if Provider(name) == ProviderOpenAI {
+		return time.Duration(cfg.OpenAI.Timeout) * time.Second
 	}
+	return time.Duration(cfg.Ollama.Timeout) * time.Second
 }
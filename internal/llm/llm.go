@@ -3,11 +3,27 @@ package llm
 
 import (
 	"context"
+	"gofigure/internal/llm/chat"
 )
 
+// Message and Options live in the chat package so providers can use them
+// without importing this package
+type (
+	Message = chat.Message
+	Options = chat.Options
+)
+
+const (
+	RoleSystem    = chat.RoleSystem
+	RoleUser      = chat.RoleUser
+	RoleAssistant = chat.RoleAssistant
+)
+
+// CharacterReply is the JSON structure characters answer in. Emotion comes
+// first so it is known before the response starts streaming.
 type CharacterReply struct {
-	Response string `json:"response"`
 	Emotion  string `json:"emotion"`
+	Response string `json:"response"`
 }
 
 // LLM defines the interface for language model providers
@@ -16,6 +32,13 @@ type LLM interface {
 	// GenerateResponse generates a response from the LLM given a prompt
 	GenerateResponse(ctx context.Context, prompt string) (string, error)
 
+	// Chat generates the next assistant message in a multi-turn conversation
+	Chat(ctx context.Context, messages []Message, opts Options) (string, error)
+
+	// ChatStream is Chat, but passes each piece of the reply to onChunk as it
+	// is generated. The complete reply is returned once the model has finished.
+	ChatStream(ctx context.Context, messages []Message, opts Options, onChunk func(string)) (string, error)
+
 	// IsModelAvailable checks if the configured model is available
 	IsModelAvailable(ctx context.Context) error
 }
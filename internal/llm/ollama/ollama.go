@@ -2,8 +2,11 @@ package ollama
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"gofigure/config"
+	"gofigure/internal/llm/chat"
 	"gofigure/internal/logger"
 	"time"
 
@@ -61,16 +64,86 @@ func (c *Client) GenerateResponse(ctx context.Context, prompt string) (string, e
 	err := c.client.Generate(timeoutCtx, req, f)
 	if err != nil {
 		c.logger.WithError(err).Error("Failed to generate response")
-		return "", fmt.Errorf("ollama generation failed: %w", err)
+		return "", fmt.Errorf("ollama generation failed: %w", statusError(err))
 	}
 
 	return response, nil
 }
 
+func (c *Client) Chat(ctx context.Context, messages []chat.Message, opts chat.Options) (string, error) {
+	return c.chat(ctx, messages, opts, false, nil)
+}
+
+// ChatStream passes each piece of the reply to onChunk as the model produces it
+func (c *Client) ChatStream(ctx context.Context, messages []chat.Message, opts chat.Options, onChunk func(string)) (string, error) {
+	return c.chat(ctx, messages, opts, true, onChunk)
+}
+
+func (c *Client) chat(ctx context.Context, messages []chat.Message, opts chat.Options, shouldStream bool, onChunk func(string)) (string, error) {
+
+	var ollamaMessages []api.Message
+	for _, msg := range messages {
+		ollamaMessages = append(ollamaMessages, api.Message{Role: msg.Role, Content: msg.Content})
+	}
+
+	req := &api.ChatRequest{
+		Model:    c.config.Model,
+		Messages: ollamaMessages,
+		Stream:   &shouldStream,
+		Options:  c.options(opts),
+		Format:   format(opts, c.config.StructuredOutput),
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(c.config.Timeout)*time.Second)
+	defer cancel()
+
+	c.logger.Debug(fmt.Sprintf("Chatting with model %s [messages:%d]", c.config.Model, len(messages)))
+
+	var response string
+	f := func(r api.ChatResponse) error {
+		response += r.Message.Content
+		if onChunk != nil && r.Message.Content != "" {
+			onChunk(r.Message.Content)
+		}
+		return nil
+	}
+
+	if err := c.client.Chat(timeoutCtx, req, f); err != nil {
+		c.logger.WithError(err).Error("Failed to chat")
+		return "", fmt.Errorf("ollama chat failed: %w", statusError(err))
+	}
+
+	return response, nil
+}
+
+func (c *Client) options(opts chat.Options) map[string]interface{} {
+	options := map[string]interface{}{
+		"temperature": opts.TemperatureOr(0.7),
+		"top_p":       0.9,
+	}
+	if opts.MaxTokens > 0 {
+		options["num_predict"] = opts.MaxTokens
+	}
+	return options
+}
+
+// format maps the requested reply format onto ollama's format parameter, which
+// takes either the string "json" or a JSON schema. Schemas are only sent to
+// models configured for structured output.
+func format(opts chat.Options, structured bool) json.RawMessage {
+	switch {
+	case len(opts.Schema) > 0 && structured:
+		return opts.Schema
+	case opts.JSON:
+		return json.RawMessage(`"json"`)
+	}
+	return nil
+}
+
 func (c *Client) IsModelAvailable(ctx context.Context) error {
 	models, err := c.client.List(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to list models: %w", err)
+		return fmt.Errorf("failed to list models: %w", statusError(err))
 	}
 
 	for _, model := range models.Models {
@@ -82,6 +155,15 @@ func (c *Client) IsModelAvailable(ctx context.Context) error {
 	return fmt.Errorf("model %s not found. Available models: %v", c.config.Model, getModelNames(models.Models))
 }
 
+// statusError converts ollama's status errors into the shared chat.StatusError
+func statusError(err error) error {
+	var se api.StatusError
+	if errors.As(err, &se) {
+		return &chat.StatusError{Provider: "ollama", StatusCode: se.StatusCode, Message: se.ErrorMessage}
+	}
+	return err
+}
+
 func getModelNames(models []api.ListModelResponse) []string {
 	names := make([]string, len(models))
 	for i, model := range models {
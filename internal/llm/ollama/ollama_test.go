@@ -0,0 +1,29 @@
+package ollama
+
+import (
+	"encoding/json"
+	"gofigure/internal/llm/chat"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	schema := json.RawMessage(`{"type": "object"}`)
+
+	tests := []struct {
+		name       string
+		opts       chat.Options
+		structured bool
+		want       string
+	}{
+		{name: "none", opts: chat.Options{}, want: ""},
+		{name: "json", opts: chat.Options{JSON: true}, want: `"json"`},
+		{name: "schema is opt in", opts: chat.Options{JSON: true, Schema: schema}, want: `"json"`},
+		{name: "structured", opts: chat.Options{JSON: true, Schema: schema}, structured: true, want: string(schema)},
+	}
+
+	for _, tt := range tests {
+		if got := format(tt.opts, tt.structured); string(got) != tt.want {
+			t.Errorf("%s: got format %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,14 +2,17 @@
 package openai
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"gofigure/config"
+	"gofigure/internal/llm/chat"
 	"gofigure/internal/logger"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -24,9 +27,23 @@ type Client struct {
 type OpenAIRequest struct {
 	Model       string          `json:"model"`
 	Messages    []OpenAIMessage `json:"messages"`
-	Temperature float64         `json:"temperature,omitempty"`
+	Temperature *float64        `json:"temperature,omitempty"`
 	MaxTokens   int             `json:"max_tokens,omitempty"`
 	Stream      bool            `json:"stream"`
+
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ResponseFormat selects JSON mode, or structured output when a schema is given
+type ResponseFormat struct {
+	Type       string      `json:"type"` // "json_object" or "json_schema"
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+type JSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict"`
 }
 
 type OpenAIMessage struct {
@@ -59,6 +76,22 @@ type OpenAIResponse struct {
 	} `json:"error,omitempty"`
 }
 
+type OpenAIStreamChunk struct {
+	ID      string `json:"id"`
+	Choices []struct {
+		Index int `json:"index"`
+		Delta struct {
+			Role    string `json:"role"`
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error,omitempty"`
+}
+
 type ModelsResponse struct {
 	Object string `json:"object"`
 	Data   []struct {
@@ -91,22 +124,100 @@ func NewClient(cfg *config.OpenAIConfig) (*Client, error) {
 }
 
 func (c *Client) GenerateResponse(ctx context.Context, prompt string) (string, error) {
-	// Parse the prompt - assuming it's JSON serialized conversation
-	var messages []struct {
-		Role    string `json:"role"`
-		Content string `json:"content"`
+	return c.Chat(ctx, []chat.Message{{Role: chat.RoleUser, Content: prompt}}, chat.Options{})
+}
+
+func (c *Client) Chat(ctx context.Context, messages []chat.Message, opts chat.Options) (string, error) {
+	req := c.newRequest(messages, opts, false)
+
+	c.logger.Debug(fmt.Sprintf("Generating response with OpenAI model %s", c.config.Model))
+
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return "", err
 	}
+	defer resp.Body.Close()
 
-	if err := json.Unmarshal([]byte(prompt), &messages); err != nil {
-		// If it's not JSON, treat as a simple prompt
-		messages = []struct {
-			Role    string `json:"role"`
-			Content string `json:"content"`
-		}{
-			{Role: "user", Content: prompt},
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	var openaiResp OpenAIResponse
+	if err := json.Unmarshal(body, &openaiResp); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if openaiResp.Error != nil {
+		return "", fmt.Errorf("openai API error: %s", openaiResp.Error.Message)
+	}
+
+	if len(openaiResp.Choices) == 0 {
+		return "", fmt.Errorf("no choices in OpenAI response")
+	}
+
+	response := openaiResp.Choices[0].Message.Content
+	c.logger.Debug(fmt.Sprintf("Generated response: %d tokens used", openaiResp.Usage.TotalTokens))
+
+	return response, nil
+}
+
+// ChatStream reads the server-sent events of a streamed completion, passing
+// each content delta to onChunk as it arrives
+func (c *Client) ChatStream(ctx context.Context, messages []chat.Message, opts chat.Options, onChunk func(string)) (string, error) {
+	req := c.newRequest(messages, opts, true)
+
+	c.logger.Debug(fmt.Sprintf("Streaming response with OpenAI model %s", c.config.Model))
+
+	resp, err := c.post(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var response strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
 		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk OpenAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return response.String(), fmt.Errorf("failed to unmarshal stream chunk: %w", err)
+		}
+
+		if chunk.Error != nil {
+			return response.String(), fmt.Errorf("openai API error: %s", chunk.Error.Message)
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content == "" {
+				continue
+			}
+			response.WriteString(choice.Delta.Content)
+			if onChunk != nil {
+				onChunk(choice.Delta.Content)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return response.String(), fmt.Errorf("failed to read stream: %w", err)
 	}
 
+	return response.String(), nil
+}
+
+func (c *Client) newRequest(messages []chat.Message, opts chat.Options, stream bool) OpenAIRequest {
 	// Convert to OpenAI format
 	var openaiMessages []OpenAIMessage
 	for _, msg := range messages {
@@ -116,24 +227,48 @@ func (c *Client) GenerateResponse(ctx context.Context, prompt string) (string, e
 		})
 	}
 
-	req := OpenAIRequest{
-		Model:       c.config.Model,
-		Messages:    openaiMessages,
-		Temperature: 0.7,
-		MaxTokens:   c.config.MaxTokens,
-		Stream:      false,
+	temperature := opts.TemperatureOr(0.7)
+
+	maxTokens := c.config.MaxTokens
+	if opts.MaxTokens > 0 {
+		maxTokens = opts.MaxTokens
 	}
 
-	c.logger.Debug(fmt.Sprintf("Generating response with OpenAI model %s", c.config.Model))
+	return OpenAIRequest{
+		Model:          c.config.Model,
+		Messages:       openaiMessages,
+		Temperature:    &temperature,
+		MaxTokens:      maxTokens,
+		Stream:         stream,
+		ResponseFormat: responseFormat(opts, c.config.StructuredOutput),
+	}
+}
 
+// responseFormat picks JSON mode for the request. Strict schemas are only sent
+// to models configured for structured output, as many compatible APIs reject them.
+func responseFormat(opts chat.Options, structured bool) *ResponseFormat {
+	switch {
+	case len(opts.Schema) > 0 && structured:
+		return &ResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &JSONSchema{Name: "reply", Schema: opts.Schema, Strict: true},
+		}
+	case opts.JSON:
+		return &ResponseFormat{Type: "json_object"}
+	}
+	return nil
+}
+
+// post sends a chat completion request, returning the response only if it succeeded
+func (c *Client) post(ctx context.Context, req OpenAIRequest) (*http.Response, error) {
 	requestBody, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewBuffer(requestBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
@@ -142,37 +277,26 @@ func (c *Client) GenerateResponse(ctx context.Context, prompt string) (string, e
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		c.logger.WithError(err).Error("Failed to make OpenAI request")
-		return "", fmt.Errorf("openai request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("openai request failed: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
 		c.logger.Error(fmt.Sprintf("OpenAI API returned status %d: %s", resp.StatusCode, string(body)))
-		return "", fmt.Errorf("openai API error: status %d", resp.StatusCode)
-	}
-
-	var openaiResp OpenAIResponse
-	if err := json.Unmarshal(body, &openaiResp); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+		return nil, &chat.StatusError{Provider: "openai", StatusCode: resp.StatusCode, Message: apiErrorMessage(body)}
 	}
 
-	if openaiResp.Error != nil {
-		return "", fmt.Errorf("openai API error: %s", openaiResp.Error.Message)
-	}
+	return resp, nil
+}
 
-	if len(openaiResp.Choices) == 0 {
-		return "", fmt.Errorf("no choices in OpenAI response")
+// apiErrorMessage pulls the message out of an OpenAI error body
+func apiErrorMessage(body []byte) string {
+	var resp OpenAIResponse
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Error == nil {
+		return ""
 	}
-
-	response := openaiResp.Choices[0].Message.Content
-	c.logger.Debug(fmt.Sprintf("Generated response: %d tokens used", openaiResp.Usage.TotalTokens))
-
-	return response, nil
+	return resp.Error.Message
 }
 
 func (c *Client) IsModelAvailable(ctx context.Context) error {
@@ -191,7 +315,7 @@ func (c *Client) IsModelAvailable(ctx context.Context) error {
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to list models: status %d, body: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("failed to list models: %w", &chat.StatusError{Provider: "openai", StatusCode: resp.StatusCode, Message: string(body)})
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -0,0 +1,38 @@
+package openai
+
+import (
+	"encoding/json"
+	"gofigure/internal/llm/chat"
+	"testing"
+)
+
+func TestResponseFormat(t *testing.T) {
+	schema := json.RawMessage(`{"type": "object"}`)
+
+	tests := []struct {
+		name       string
+		opts       chat.Options
+		structured bool
+		want       string
+	}{
+		{name: "none", opts: chat.Options{}, want: ""},
+		{name: "json", opts: chat.Options{JSON: true}, want: "json_object"},
+		{name: "schema is opt in", opts: chat.Options{JSON: true, Schema: schema}, want: "json_object"},
+		{name: "structured", opts: chat.Options{JSON: true, Schema: schema}, structured: true, want: "json_schema"},
+		{name: "structured without a schema", opts: chat.Options{JSON: true}, structured: true, want: "json_object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := responseFormat(tt.opts, tt.structured)
+			switch {
+			case got == nil && tt.want != "":
+				t.Errorf("got no response_format, want %s", tt.want)
+			case got != nil && got.Type != tt.want:
+				t.Errorf("got response_format %s, want %q", got.Type, tt.want)
+			case got != nil && got.Type == "json_schema" && (got.JSONSchema == nil || !got.JSONSchema.Strict):
+				t.Errorf("got json_schema without a strict schema: %+v", got.JSONSchema)
+			}
+		})
+	}
+}
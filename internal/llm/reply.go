@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNoJSON is returned when a reply contains no JSON object at all
+var ErrNoJSON = errors.New("no JSON object in reply")
+
+// CharacterReplySchema constrains replies on providers with native JSON schema support
+var CharacterReplySchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "emotion": {"type": "string"},
+    "response": {"type": "string"}
+  },
+  "required": ["emotion", "response"],
+  "additionalProperties": false
+}`)
+
+// DefaultEmotion is used when the model forgets to say how the character feels
+const DefaultEmotion = "neutral"
+
+// ParseCharacterReply extracts a CharacterReply from whatever the model wrote,
+// tolerating markdown fences, prose around the JSON and a missing emotion
+func ParseCharacterReply(raw string) (*CharacterReply, error) {
+	obj, err := ExtractJSON(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply CharacterReply
+	if err := json.Unmarshal([]byte(obj), &reply); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reply: %w", err)
+	}
+
+	if strings.TrimSpace(reply.Response) == "" {
+		return nil, errors.New("reply has no response")
+	}
+	if strings.TrimSpace(reply.Emotion) == "" {
+		reply.Emotion = DefaultEmotion
+	}
+
+	return &reply, nil
+}
+
+// PlainTextReply treats a reply that never became JSON as the response itself
+func PlainTextReply(raw string) (*CharacterReply, bool) {
+	text := strings.TrimSpace(stripFences(raw))
+	if text == "" {
+		return nil, false
+	}
+	return &CharacterReply{Response: text, Emotion: DefaultEmotion}, true
+}
+
+// ExtractJSON returns the first complete JSON object in s
+func ExtractJSON(s string) (string, error) {
+	s = stripFences(s)
+
+	start := strings.Index(s, "{")
+	if start < 0 {
+		return "", ErrNoJSON
+	}
+
+	depth := 0
+	inString := false
+	escaped := false
+
+	for i := start; i < len(s); i++ {
+		ch := s[i]
+
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return s[start : i+1], nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w: unterminated object", ErrNoJSON)
+}
+
+// stripFences removes markdown code fences such as ```json ... ```
+func stripFences(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") {
+		return s
+	}
+
+	s = strings.TrimPrefix(s, "```")
+	if nl := strings.Index(s, "\n"); nl >= 0 && !strings.Contains(s[:nl], "{") {
+		s = s[nl+1:] // drop the language tag
+	}
+	if end := strings.LastIndex(s, "```"); end >= 0 {
+		s = s[:end]
+	}
+	return strings.TrimSpace(s)
+}
+
+// CorrectionPrompt asks the model to repeat its last reply in the required structure
+const CorrectionPrompt = `Your last reply could not be read. Reply again, staying in character, using only this JSON structure and nothing else: {"emotion": string, "response": string}`
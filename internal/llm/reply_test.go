@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStripFences(t *testing.T) {
+	tests := map[string]string{
+		`{"a": 1}`:                            `{"a": 1}`,
+		"  {\"a\": 1}\n":                      `{"a": 1}`,
+		"```json\n{\"a\": 1}\n```":            `{"a": 1}`,
+		"```\n{\"a\": 1}\n```":                `{"a": 1}`,
+		"```{\"a\": 1}```":                    `{"a": 1}`,
+		"```json\n{\"a\": 1}":                 `{"a": 1}`, // never closed
+		"Here you go:\n```json\n{}\n```":      "Here you go:\n```json\n{}\n```",
+		"```text\nI was out all evening\n```": "I was out all evening",
+	}
+
+	for in, want := range tests {
+		if got := stripFences(in); got != want {
+			t.Errorf("stripFences(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{name: "bare", in: `{"a": 1}`, want: `{"a": 1}`},
+		{name: "fenced", in: "```json\n{\"a\": 1}\n```", want: `{"a": 1}`},
+		{name: "prose around it", in: `Sure! {"a": 1} Hope that helps.`, want: `{"a": 1}`},
+		{name: "nested", in: `{"a": {"b": [1, {}]}} {"c": 2}`, want: `{"a": {"b": [1, {}]}}`},
+		{name: "braces in strings", in: `{"a": "}{", "b": "\"}"}`, want: `{"a": "}{", "b": "\"}"}`},
+		{name: "escaped backslash before a quote", in: `{"a": "\\"} trailing`, want: `{"a": "\\"}`},
+		{name: "no object", in: `I refuse to answer`, wantErr: true},
+		{name: "unterminated", in: `{"a": {"b": 1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractJSON(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrNoJSON) {
+					t.Errorf("got (%q, %v), want ErrNoJSON", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got (%q, %v), want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCharacterReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    CharacterReply
+		wantErr bool
+	}{
+		{name: "reply", raw: `{"emotion": "angry", "response": "How dare you!"}`, want: CharacterReply{Emotion: "angry", Response: "How dare you!"}},
+		{name: "fenced with prose", raw: "Of course.\n" + `{"emotion": "calm", "response": "I was out."}`, want: CharacterReply{Emotion: "calm", Response: "I was out."}},
+		{name: "missing emotion", raw: `{"response": "I was out."}`, want: CharacterReply{Emotion: DefaultEmotion, Response: "I was out."}},
+		{name: "blank emotion", raw: `{"emotion": " ", "response": "I was out."}`, want: CharacterReply{Emotion: DefaultEmotion, Response: "I was out."}},
+		{name: "extra fields", raw: `{"emotion": "sad", "response": "No.", "thoughts": "lie"}`, want: CharacterReply{Emotion: "sad", Response: "No."}},
+		{name: "no response", raw: `{"emotion": "calm"}`, wantErr: true},
+		{name: "blank response", raw: `{"emotion": "calm", "response": "  "}`, wantErr: true},
+		{name: "wrong type", raw: `{"emotion": "calm", "response": 3}`, wantErr: true},
+		{name: "plain text", raw: `I was out all evening.`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCharacterReply(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil || *got != tt.want {
+				t.Errorf("got (%+v, %v), want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlainTextReply(t *testing.T) {
+	if _, ok := PlainTextReply("```\n\n```"); ok {
+		t.Error("empty reply used as a response")
+	}
+	reply, ok := PlainTextReply("```\nI was out.\n```")
+	if !ok || reply.Response != "I was out." || reply.Emotion != DefaultEmotion {
+		t.Errorf("got (%+v, %v), want the text with the default emotion", reply, ok)
+	}
+}
@@ -0,0 +1,137 @@
+package llm
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// ReplyStream decodes a CharacterReply while its JSON is still arriving, so
+// the response can be shown (and spoken) before the model has finished.
+type ReplyStream struct {
+	raw     strings.Builder
+	emitted int
+
+	// OnText receives each newly decoded piece of the "response" field
+	OnText func(text string)
+}
+
+// Write appends a chunk of the model's raw output
+func (s *ReplyStream) Write(chunk string) {
+	s.raw.WriteString(chunk)
+
+	response, _ := partialStringField(s.raw.String(), "response")
+	if len(response) > s.emitted && s.OnText != nil {
+		s.OnText(response[s.emitted:])
+	}
+	s.emitted = max(s.emitted, len(response))
+}
+
+// Emotion returns the "emotion" field once it has fully arrived
+func (s *ReplyStream) Emotion() (string, bool) {
+	return partialStringField(s.raw.String(), "emotion")
+}
+
+// Reply returns as much of the reply as has been decoded, or false if no
+// response text has arrived yet
+func (s *ReplyStream) Reply() (*CharacterReply, bool) {
+	response, _ := partialStringField(s.raw.String(), "response")
+	if strings.TrimSpace(response) == "" {
+		return nil, false
+	}
+
+	emotion, _ := s.Emotion()
+	if strings.TrimSpace(emotion) == "" {
+		emotion = DefaultEmotion
+	}
+	return &CharacterReply{Emotion: emotion, Response: response}, true
+}
+
+// Raw returns everything written so far
+func (s *ReplyStream) Raw() string {
+	return s.raw.String()
+}
+
+// partialStringField decodes as much of a JSON string field as has arrived.
+// complete is true once the closing quote has been seen.
+func partialStringField(raw, key string) (value string, complete bool) {
+	idx := strings.Index(raw, `"`+key+`"`)
+	if idx < 0 {
+		return "", false
+	}
+
+	rest := strings.TrimLeft(raw[idx+len(key)+2:], " \t\r\n")
+	if !strings.HasPrefix(rest, ":") {
+		return "", false
+	}
+	rest = strings.TrimLeft(rest[1:], " \t\r\n")
+	if !strings.HasPrefix(rest, `"`) {
+		return "", false
+	}
+	rest = rest[1:]
+
+	var b strings.Builder
+	for i := 0; i < len(rest); i++ {
+		switch ch := rest[i]; ch {
+		case '"':
+			return b.String(), true
+
+		case '\\':
+			if i+1 >= len(rest) {
+				return b.String(), false
+			}
+			i++
+			switch rest[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'u':
+				r, n, ok := decodeUnicodeEscape(rest[i-1:])
+				if !ok {
+					return b.String(), false
+				}
+				b.WriteRune(r)
+				i += n - 2
+			default: // \" \\ \/
+				b.WriteByte(rest[i])
+			}
+
+		default:
+			b.WriteByte(ch)
+		}
+	}
+
+	return b.String(), false
+}
+
+// decodeUnicodeEscape decodes \uXXXX (or a \uXXXX\uXXXX surrogate pair) at the
+// start of s, returning the rune and the number of bytes consumed
+func decodeUnicodeEscape(s string) (rune, int, bool) {
+	if len(s) < 6 {
+		return 0, 0, false
+	}
+	r1, err := strconv.ParseUint(s[2:6], 16, 32)
+	if err != nil {
+		return '�', 6, true
+	}
+
+	if !utf16.IsSurrogate(rune(r1)) {
+		return rune(r1), 6, true
+	}
+
+	if len(s) < 12 {
+		return 0, 0, false
+	}
+	r2, err := strconv.ParseUint(s[8:12], 16, 32)
+	if err != nil || s[6:8] != `\u` {
+		return '�', 6, true
+	}
+	return utf16.DecodeRune(rune(r1), rune(r2)), 12, true
+}
@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+// u writes JSON \u escapes for the given hex code units
+func u(units ...string) string {
+	return `\u` + strings.Join(units, `\u`)
+}
+
+func TestPartialStringField(t *testing.T) {
+	tests := []struct {
+		name         string
+		raw          string
+		want         string
+		wantComplete bool
+	}{
+		{"no field yet", `{"emo`, "", false},
+		{"no value yet", `{"response":`, "", false},
+		{"not a string", `{"response": 42}`, "", false},
+		{"partial", `{"response": "I was in the`, "I was in the", false},
+		{"complete", `{"response": "I was out.", "emotion": "calm"}`, "I was out.", true},
+		{"spaced", "{\"response\" :\n \"yes\"}", "yes", true},
+		{"escapes", `{"response": "He said \"no\"\nthen left\\"}`, "He said \"no\"\nthen left\\", true},
+		{"escaped quote does not end it", `{"response": "a \"quoted`, `a "quoted`, false},
+		{"escape split across chunks", `{"response": "tab\`, "tab", false},
+		{"unicode", `{"response": "caf` + u("00e9") + `"}`, "caf\u00e9", true},
+		{"unicode split across chunks", `{"response": "caf\u00`, "caf", false},
+		{"surrogate pair", `{"response": "` + u("d83d", "dd0d") + `"}`, "\U0001F50D", true},
+		{"half a surrogate pair", `{"response": "` + u("d83d"), "", false},
+		{"other field", `{"emotion": "calm", "response": "hi"}`, "hi", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, complete := partialStringField(tt.raw, "response")
+			if got != tt.want || complete != tt.wantComplete {
+				t.Errorf("got (%q, %v), want (%q, %v)", got, complete, tt.want, tt.wantComplete)
+			}
+		})
+	}
+}
+
+func TestDecodeUnicodeEscape(t *testing.T) {
+	tests := []struct {
+		s      string
+		want   rune
+		wantN  int
+		wantOk bool
+	}{
+		{u("00e9") + " rest", '\u00e9', 6, true},
+		{u("0041"), 'A', 6, true},
+		{`\u00`, 0, 0, false},
+		{u("zzzz"), '\uFFFD', 6, true},
+		{u("d83d", "dd0d"), '\U0001F50D', 12, true},
+		{u("d83d") + `\ud`, 0, 0, false},
+		{u("d83d") + " and more", '\uFFFD', 6, true},
+	}
+
+	for _, tt := range tests {
+		r, n, ok := decodeUnicodeEscape(tt.s)
+		if r != tt.want || n != tt.wantN || ok != tt.wantOk {
+			t.Errorf("decodeUnicodeEscape(%q) = (%q, %d, %v), want (%q, %d, %v)", tt.s, r, n, ok, tt.want, tt.wantN, tt.wantOk)
+		}
+	}
+}
+
+func TestReplyStreamEmitsEachPieceOnce(t *testing.T) {
+	var got []string
+	s := &ReplyStream{OnText: func(text string) { got = append(got, text) }}
+
+	for _, chunk := range []string{`{"emotion": "ca`, `lm", "resp`, `onse": "I was`, ` out`, `."}`} {
+		s.Write(chunk)
+	}
+
+	if len(got) != 3 || got[0]+got[1]+got[2] != "I was out." {
+		t.Errorf("emitted %q, want the response in 3 pieces", got)
+	}
+	if reply, ok := s.Reply(); !ok || reply.Emotion != "calm" {
+		t.Errorf("got reply %+v, want emotion calm", reply)
+	}
+}
@@ -0,0 +1,100 @@
+package sst
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/gen2brain/malgo"
+)
+
+// Microphone records 16-bit mono PCM from the default capture device
+type Microphone struct {
+	context    *malgo.AllocatedContext
+	device     *malgo.Device
+	sampleRate int
+}
+
+func NewMicrophone(sampleRate int) (*Microphone, error) {
+	malgoCtx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize malgo context: %w", err)
+	}
+
+	return &Microphone{context: malgoCtx, sampleRate: sampleRate}, nil
+}
+
+// Start begins recording, handing each captured chunk to sink. sink runs on
+// the audio thread and owns the chunk it is given. A microphone never runs
+// dry, so onEnd is not used.
+func (m *Microphone) Start(sink func(pcm []byte), onEnd func()) error {
+	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
+	deviceConfig.Capture.Format = malgo.FormatS16
+	deviceConfig.Capture.Channels = 1
+	deviceConfig.SampleRate = uint32(m.sampleRate)
+	deviceConfig.PeriodSizeInFrames = 1024
+	deviceConfig.Periods = 4
+
+	callbacks := malgo.DeviceCallbacks{
+		Data: func(_, inputSample []byte, _ uint32) {
+			if len(inputSample) > 0 {
+				// malgo reuses its buffer once the callback returns
+				sink(append([]byte(nil), inputSample...))
+			}
+		},
+	}
+
+	device, err := malgo.InitDevice(m.context.Context, deviceConfig, callbacks)
+	if err != nil {
+		return fmt.Errorf("failed to initialize audio device: %w", err)
+	}
+
+	if err := device.Start(); err != nil {
+		device.Uninit()
+		return fmt.Errorf("failed to start audio device: %w", err)
+	}
+
+	m.device = device
+	return nil
+}
+
+// Stop ends the recording. sink is not called again once Stop returns.
+func (m *Microphone) Stop() {
+	if m.device != nil {
+		m.device.Stop()
+		m.device.Uninit()
+		m.device = nil
+	}
+}
+
+func (m *Microphone) Close() error {
+	m.Stop()
+
+	if m.context == nil {
+		return nil
+	}
+	err := m.context.Uninit()
+	m.context = nil
+	return err
+}
+
+// encodeWAV wraps 16-bit mono PCM in a WAV header
+func encodeWAV(pcm []byte, sampleRate int) []byte {
+	const headerSize = 44
+	wav := make([]byte, headerSize, headerSize+len(pcm))
+
+	copy(wav[0:], "RIFF")
+	binary.LittleEndian.PutUint32(wav[4:], uint32(36+len(pcm)))
+	copy(wav[8:], "WAVE")
+	copy(wav[12:], "fmt ")
+	binary.LittleEndian.PutUint32(wav[16:], 16)                   // fmt chunk size
+	binary.LittleEndian.PutUint16(wav[20:], 1)                    // PCM
+	binary.LittleEndian.PutUint16(wav[22:], 1)                    // mono
+	binary.LittleEndian.PutUint32(wav[24:], uint32(sampleRate))   // sample rate
+	binary.LittleEndian.PutUint32(wav[28:], uint32(sampleRate*2)) // byte rate
+	binary.LittleEndian.PutUint16(wav[32:], 2)                    // block align
+	binary.LittleEndian.PutUint16(wav[34:], 16)                   // bits per sample
+	copy(wav[36:], "data")
+	binary.LittleEndian.PutUint32(wav[40:], uint32(len(pcm)))
+
+	return append(wav, pcm...)
+}
@@ -11,13 +11,15 @@ func NewDummySST() *DummySST {
 	return &DummySST{}
 }
 
-func (d *DummySST) StartListening(ctx context.Context) (<-chan string, error) {
+func (d *DummySST) StartListening(ctx context.Context) (<-chan Result, error) {
 	// Return an empty channel that will never receive data
-	ch := make(chan string)
+	ch := make(chan Result)
 	close(ch)
 	return ch, nil
 }
 
+func (d *DummySST) SetPhraseHints(phrases []string) {}
+
 func (d *DummySST) StopListening() error {
 	return nil
 }
@@ -0,0 +1,68 @@
+package sst
+
+import (
+	"context"
+	"fmt"
+	"gofigure/config"
+)
+
+// Listening modes
+const (
+	ModePushToTalk = "push_to_talk"
+	ModeVAD        = "vad"
+)
+
+// NewSst creates the provider named by sst.provider. A disabled config gives
+// the dummy provider.
+func NewSst(ctx context.Context, cfg *config.SstConfig) (Sst, error) {
+	if !cfg.Enabled {
+		return NewDummySST(), nil
+	}
+
+	var vad *config.VADConfig
+	switch cfg.Mode {
+	case ModeVAD:
+		vad = &cfg.VAD
+	case ModePushToTalk, "":
+	default:
+		return nil, fmt.Errorf("unsupported sst mode: %s", cfg.Mode)
+	}
+
+	switch cfg.Provider {
+	case "google":
+		source, err := newAudioSource(cfg, cfg.SampleRate)
+		if err != nil {
+			return nil, err
+		}
+		g, err := NewGoogleSST(ctx, cfg.LanguageCode, cfg.SampleRate, source)
+		if err != nil {
+			source.Close()
+			return nil, err
+		}
+		return g.WithVAD(vad), nil
+
+	case "whisper":
+		source, err := newAudioSource(cfg, whisperSampleRate)
+		if err != nil {
+			return nil, err
+		}
+		w, err := NewWhisperSST(cfg.WhisperBinary, cfg.WhisperModel, cfg.LanguageCode, source)
+		if err != nil {
+			source.Close()
+			return nil, err
+		}
+		return w.WithVAD(vad), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported sst provider: %s", cfg.Provider)
+	}
+}
+
+// newAudioSource replays sst.audio_input when set, and listens to the
+// microphone otherwise
+func newAudioSource(cfg *config.SstConfig, sampleRate int) (AudioSource, error) {
+	if len(cfg.AudioInput) > 0 {
+		return NewFileSource(cfg.AudioInput, sampleRate), nil
+	}
+	return NewMicrophone(sampleRate)
+}
@@ -3,264 +3,238 @@ package sst
 import (
 	"context"
 	"fmt"
+	"gofigure/config"
 	"gofigure/internal/logger"
-	"time"
+	"io"
+	"sync"
 
 	speech "cloud.google.com/go/speech/apiv1"
-	"github.com/gen2brain/malgo"
 	speechpb "google.golang.org/genproto/googleapis/cloud/speech/v1"
 )
 
+// GoogleSST streams audio to Google's StreamingRecognize, so words are
+// recognised as they are spoken rather than in fixed chunks
 type GoogleSST struct {
 	client       *speech.Client
 	languageCode string
 	sampleRate   int
 
-	// Audio capture components
-	malgoContext *malgo.AllocatedContext
-	device       *malgo.Device
-	audioBuffer  []byte
-	recording    bool
+	listener *listener
 
-	// Add these for debugging
-	totalAudioCaptured int64
-	chunksProcessed    int
-	lastChunkSize      int
-
-	// Channels for communication
-	transcriptChan chan string
-	errorChan      chan error
+	mu    sync.Mutex
+	hints []string
 }
 
-func NewGoogleSST(ctx context.Context, languageCode string, sampleRate int) (*GoogleSST, error) {
+// NewGoogleSST recognises audio from source, which must deliver sampleRate
+// 16-bit mono PCM. The recognizer takes ownership of the source.
+func NewGoogleSST(ctx context.Context, languageCode string, sampleRate int, source AudioSource) (*GoogleSST, error) {
 	client, err := speech.NewClient(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Google Speech client: %w", err)
 	}
 
-	// Initialize malgo context
-	malgoCtx, err := malgo.InitContext(nil, malgo.ContextConfig{}, nil)
-	if err != nil {
-		client.Close()
-		return nil, fmt.Errorf("failed to initialize malgo context: %w", err)
-	}
-
 	return &GoogleSST{
-		client:         client,
-		languageCode:   languageCode,
-		sampleRate:     sampleRate,
-		malgoContext:   malgoCtx,
-		transcriptChan: make(chan string, 10),
-		errorChan:      make(chan error, 10),
+		client:       client,
+		languageCode: languageCode,
+		sampleRate:   sampleRate,
+		listener:     newListener(source, sampleRate),
 	}, nil
 }
 
-func (g *GoogleSST) StartListening(ctx context.Context) (<-chan string, error) {
-	logger.New().Debug("[google-sst] start-listening called")
-	if g.recording {
-		return g.transcriptChan, nil
-	}
+// phraseBoost weights phrase hints over similar sounding words. Google accepts
+// 0 to 20; higher values risk hearing hints that were never said.
+const phraseBoost = 10
 
-	// Reset debugging counters
-	g.totalAudioCaptured = 0
-	g.chunksProcessed = 0
-	g.lastChunkSize = 0
-
-	// Configure audio device with better settings
-	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
-	deviceConfig.Capture.Format = malgo.FormatS16
-	deviceConfig.Capture.Channels = 1
-	deviceConfig.SampleRate = uint32(g.sampleRate)
-	deviceConfig.PeriodSizeInFrames = 1024 // Smaller buffer for more responsive capture
-	deviceConfig.Periods = 4
-
-	logger.New().Debug(fmt.Sprintf("[google-sst] audio config: format=%v, channels=%d, sampleRate=%d, periodSize=%d",
-		deviceConfig.Capture.Format, deviceConfig.Capture.Channels, deviceConfig.SampleRate, deviceConfig.PeriodSizeInFrames))
-
-	deviceCallbacks := malgo.DeviceCallbacks{
-		Data: func(outputSample, inputSample []byte, frameCount uint32) {
-			if g.recording && len(inputSample) > 0 {
-				g.audioBuffer = append(g.audioBuffer, inputSample...)
-
-				// Log every 10th callback to avoid spam
-				if len(g.audioBuffer)%(g.sampleRate*2) == 0 { // Every ~1 second worth of audio
-					logger.New().Debug(fmt.Sprintf("[google-sst] audio captured: %d bytes total, frames in this callback: %d",
-						len(g.audioBuffer), frameCount))
-				}
-			}
-		},
-	}
+// alternatives let the engine offer "did you mean" choices
+const maxAlternatives = 3
 
-	device, err := malgo.InitDevice(g.malgoContext.Context, deviceConfig, deviceCallbacks)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize audio device: %w", err)
-	}
-
-	if err := device.Start(); err != nil {
-		device.Uninit()
-		return nil, fmt.Errorf("failed to start audio device: %w", err)
-	}
+// Google rejects phrases longer than this
+const maxPhraseLength = 100
 
-	logger.New().Debug("[google-sst] audio device started successfully")
-	g.device = device
-	g.recording = true
-
-	return g.transcriptChan, nil
+// WithVAD stops each session by itself once the detective has finished
+// speaking. A nil config keeps push-to-talk.
+func (g *GoogleSST) WithVAD(cfg *config.VADConfig) *GoogleSST {
+	g.listener.vad = cfg
+	return g
 }
 
-func (g *GoogleSST) StopListening() error {
-	logger.New().Debug("[google-sst] stop-listening called")
-	if !g.recording {
-		return nil
-	}
-
-	g.recording = false
+// StartListening opens a streaming recognition session. Interim results arrive
+// while the detective speaks; the final ones follow the end of the session,
+// after which the channel is closed. Cancelling ctx abandons the session.
+func (g *GoogleSST) StartListening(ctx context.Context) (<-chan Result, error) {
+	logger.New().Debug("[google-sst] start-listening called")
 
-	// Process any remaining audio in the buffer before stopping
-	if len(g.audioBuffer) > 0 {
-		logger.New().Debug(fmt.Sprintf("[google-sst] processing final audio buffer: %d bytes", len(g.audioBuffer)))
+	g.mu.Lock()
+	contexts := g.speechContexts()
+	g.mu.Unlock()
 
-		// Create a context for the final processing with a reasonable timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+	results := make(chan Result, 16)
 
-		err := g.ProcessAudioChunk(ctx)
+	err := g.listener.start(ctx, func(ctx context.Context) (*recognition, error) {
+		stream, err := g.openStream(ctx, contexts)
 		if err != nil {
-			logger.New().WithError(err).Error("failed to process final audio chunk")
-		} else {
-			logger.New().Debug("[google-sst] final audio chunk processed successfully")
+			return nil, err
 		}
-	} else {
-		logger.New().Debug("[google-sst] no remaining audio to process")
-	}
 
-	if g.device != nil {
-		g.device.Stop()
-		g.device.Uninit()
-		g.device = nil
+		go g.receive(ctx, stream, results)
+
+		sent := 0
+		failed := false
+		return &recognition{
+			audio: func(pcm []byte) {
+				if failed {
+					return
+				}
+				err := stream.Send(&speechpb.StreamingRecognizeRequest{
+					StreamingRequest: &speechpb.StreamingRecognizeRequest_AudioContent{AudioContent: pcm},
+				})
+				if err != nil {
+					logger.New().WithError(err).Error("[google-sst] failed to send audio")
+					failed = true
+					return
+				}
+				sent += len(pcm)
+			},
+			finish: func(ctx context.Context) {
+				logger.New().Debug(fmt.Sprintf("[google-sst] sent %d bytes of audio", sent))
+				if ctx.Err() != nil {
+					return // the stream has gone with the context
+				}
+				// half-close the stream so Google sends its final results
+				if err := stream.CloseSend(); err != nil {
+					logger.New().WithError(err).Error("[google-sst] failed to close recognition stream")
+				}
+			},
+		}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	logger.New().Debug("[google-sst] audio source started successfully")
+	return results, nil
 }
 
-func (g *GoogleSST) IsListening() bool {
-	return g.recording
-}
+// openStream starts a streaming recognition and sends its configuration
+func (g *GoogleSST) openStream(ctx context.Context, contexts []*speechpb.SpeechContext) (speechpb.Speech_StreamingRecognizeClient, error) {
+	stream, err := g.client.StreamingRecognize(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recognition stream: %w", err)
+	}
+
+	err = stream.Send(&speechpb.StreamingRecognizeRequest{
+		StreamingRequest: &speechpb.StreamingRecognizeRequest_StreamingConfig{
+			StreamingConfig: &speechpb.StreamingRecognitionConfig{
+				Config: &speechpb.RecognitionConfig{
+					Encoding:                   speechpb.RecognitionConfig_LINEAR16,
+					SampleRateHertz:            int32(g.sampleRate),
+					LanguageCode:               g.languageCode,
+					EnableAutomaticPunctuation: true,
+					Model:                      "latest_long", // Better for longer phrases
+					SpeechContexts:             contexts,
+					MaxAlternatives:            maxAlternatives,
+				},
+				InterimResults: true,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure recognition stream: %w", err)
+	}
 
-func (g *GoogleSST) Provider() string {
-	return "google"
+	return stream, nil
 }
 
-// Add debugging method to check audio capture stats
-func (g *GoogleSST) GetDebugStats() map[string]interface{} {
-	return map[string]interface{}{
-		"recording":           g.recording,
-		"totalAudioCaptured":  g.totalAudioCaptured,
-		"chunksProcessed":     g.chunksProcessed,
-		"lastChunkSize":       g.lastChunkSize,
-		"audioBufferSize":     len(g.audioBuffer),
-		"expectedBytesPerSec": g.sampleRate * 2, // 16-bit mono
+func (g *GoogleSST) SetPhraseHints(phrases []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.hints = nil
+	for _, phrase := range phrases {
+		if len(phrase) <= maxPhraseLength {
+			g.hints = append(g.hints, phrase)
+		}
 	}
+	logger.New().Debug(fmt.Sprintf("[google-sst] %d phrase hints set", len(g.hints)))
 }
 
-// ProcessAudioChunk processes the current audio buffer and sends to Google STT
-func (g *GoogleSST) ProcessAudioChunk(ctx context.Context) error {
-	if len(g.audioBuffer) == 0 {
-		logger.New().Debug("[google-sst] no audio data in buffer to process")
+// speechContexts biases recognition towards the phrase hints. Called with the
+// lock held.
+func (g *GoogleSST) speechContexts() []*speechpb.SpeechContext {
+	if len(g.hints) == 0 {
 		return nil
 	}
+	return []*speechpb.SpeechContext{{Phrases: g.hints, Boost: phraseBoost}}
+}
 
-	// Copy buffer to avoid race conditions
-	audioData := make([]byte, len(g.audioBuffer))
-	copy(audioData, g.audioBuffer)
-	chunkSize := len(g.audioBuffer)
-	g.audioBuffer = nil // Clear buffer
-
-	// Update debugging stats
-	g.totalAudioCaptured += int64(chunkSize)
-	g.chunksProcessed++
-	g.lastChunkSize = chunkSize
-
-	logger.New().Debug(fmt.Sprintf("[google-sst] processing audio chunk: size=%d bytes, total_captured=%d bytes, chunks_processed=%d",
-		chunkSize, g.totalAudioCaptured, g.chunksProcessed))
-
-	// Check if we have enough audio data (minimum ~1 second at 16kHz mono = 32KB)
-	minAudioSize := g.sampleRate * 2 // 2 bytes per sample for 16-bit audio, 1 second
-	if chunkSize < minAudioSize {
-		logger.New().Debug(fmt.Sprintf("[google-sst] chunk too small for reliable STT: %d bytes (min: %d bytes)", chunkSize, minAudioSize))
-	}
-
-	// Send to Google STT
-	req := &speechpb.RecognizeRequest{
-		Config: &speechpb.RecognitionConfig{
-			Encoding:                   speechpb.RecognitionConfig_LINEAR16,
-			SampleRateHertz:            int32(g.sampleRate),
-			LanguageCode:               g.languageCode,
-			EnableAutomaticPunctuation: true,
-			Model:                      "latest_long", // Better for longer phrases
-		},
-		Audio: &speechpb.RecognitionAudio{
-			AudioSource: &speechpb.RecognitionAudio_Content{
-				Content: audioData,
-			},
-		},
-	}
-
-	logger.New().Debug("[google-sst] sending request to Google STT...")
-	resp, err := g.client.Recognize(ctx, req)
-	if err != nil {
-		logger.New().WithError(err).Error(fmt.Sprintf("[google-sst] STT request failed for chunk %d", g.chunksProcessed))
-		return err
-	}
+// receive turns recognition responses into results, closing the channel when
+// the stream ends
+func (g *GoogleSST) receive(ctx context.Context, stream speechpb.Speech_StreamingRecognizeClient, results chan<- Result) {
+	defer close(results)
 
-	logger.New().Debug(fmt.Sprintf("[google-sst] received %d results from Google STT", len(resp.Results)))
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			logger.New().Debug("[google-sst] recognition stream finished")
+			return
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.New().WithError(err).Error("[google-sst] recognition stream failed")
+			}
+			return
+		}
 
-	for i, result := range resp.Results {
-		logger.New().Debug(fmt.Sprintf("[google-sst] result %d: alternatives=%d", i, len(result.Alternatives)))
+		if resp.Error != nil {
+			logger.New().Error(fmt.Sprintf("[google-sst] recognition error: %s", resp.Error.Message))
+		}
 
-		if len(result.Alternatives) > 0 {
-			transcript := result.Alternatives[0].Transcript
-			confidence := result.Alternatives[0].Confidence
+		for _, result := range resp.Results {
+			if len(result.Alternatives) == 0 {
+				continue
+			}
 
-			logger.New().Debug(fmt.Sprintf("[google-sst] transcript: '%s' (confidence: %.2f)", transcript, confidence))
+			best := result.Alternatives[0]
+			r := Result{Transcript: best.Transcript, IsFinal: result.IsFinal, Confidence: best.Confidence}
+			for _, alt := range result.Alternatives[1:] {
+				r.Alternatives = append(r.Alternatives, alt.Transcript)
+			}
+			logger.New().Debug(fmt.Sprintf("[google-sst] transcript: '%s' (final: %t, confidence: %.2f, stability: %.2f, alternatives: %d)",
+				r.Transcript, r.IsFinal, r.Confidence, result.Stability, len(r.Alternatives)))
 
 			select {
-			case g.transcriptChan <- transcript:
-				logger.New().Debug("[google-sst] transcript sent to channel successfully")
+			case results <- r:
 			case <-ctx.Done():
-				logger.New().WithError(ctx.Err()).Debug("[google-sst] context cancelled while sending transcript")
-				return ctx.Err()
+				return
 			}
-		} else {
-			logger.New().Debug("[google-sst] no alternatives in result")
 		}
 	}
+}
 
-	if len(resp.Results) == 0 {
-		logger.New().Debug("[google-sst] no results from Google STT - audio may be too quiet, too short, or unclear")
-	}
-
-	logger.New().Debug("[google-sst] process audio chunk finished successfully")
+// StopListening ends the session. Google's final results follow on the
+// session's channel.
+func (g *GoogleSST) StopListening() error {
+	logger.New().Debug("[google-sst] stop-listening called")
+	g.listener.stop()
 	return nil
 }
 
-// Close cleans up resources
+func (g *GoogleSST) IsListening() bool {
+	return g.listener.listening()
+}
+
+func (g *GoogleSST) Provider() string {
+	return "google"
+}
+
+// Close abandons any session and cleans up resources
 func (g *GoogleSST) Close() error {
 	logger.New().Debug("[google-sst] close called")
 
-	err := g.StopListening()
-	if err != nil {
+	if err := g.listener.close(); err != nil {
 		return err
 	}
 
-	if g.malgoContext != nil {
-		err := g.malgoContext.Uninit()
-		if err != nil {
-			return err
-		}
-		g.malgoContext = nil
-	}
-
 	if g.client != nil {
 		err := g.client.Close()
 		if err != nil {
@@ -269,8 +243,5 @@ func (g *GoogleSST) Close() error {
 		g.client = nil
 	}
 
-	close(g.transcriptChan)
-	close(g.errorChan)
-
 	return nil
 }
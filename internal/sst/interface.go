@@ -2,17 +2,37 @@ package sst
 
 import "context"
 
+// Result is a piece of recognised speech. Interim results are the provider's
+// best guess so far and may change; a final result is settled.
+type Result struct {
+	Transcript string
+	IsFinal    bool
+
+	// Confidence runs from 0 to 1. It is 0 when the provider doesn't say, as
+	// for interim results and whisper.
+	Confidence float32
+	// Alternatives are less likely readings of the same speech, best first
+	Alternatives []string
+}
+
 // Sst defines the interface for speech-to-text services
 type Sst interface {
-	// StartListening begins audio capture and returns a channel for the transcribed text
-	StartListening(ctx context.Context) (<-chan string, error)
-	
+	// StartListening begins audio capture for a new session and returns a
+	// channel of results. The channel is closed once the session's final
+	// results have been delivered after StopListening, or when ctx is done,
+	// which abandons the session. Only the provider closes it.
+	StartListening(ctx context.Context) (<-chan Result, error)
+
+	// SetPhraseHints lists names and words the recognizer should expect, such
+	// as the suspects in the current mystery. They apply from the next session.
+	SetPhraseHints(phrases []string)
+
 	// StopListening stops audio capture
 	StopListening() error
-	
+
 	// IsListening returns true if currently capturing audio
 	IsListening() bool
-	
+
 	// Provider returns the name of the SST provider
 	Provider() string
-}
\ No newline at end of file
+}
@@ -0,0 +1,179 @@
+package sst
+
+import (
+	"context"
+	"errors"
+	"gofigure/config"
+	"gofigure/internal/logger"
+	"sync"
+)
+
+// listener runs listening sessions over an audio source. Each session has a
+// single owner goroutine which alone stops the source and hands audio to the
+// recognizer, so recognizers need no locks around what they build up.
+// StopListening, Close, voice activity detection, the end of a recording and
+// the session's context only signal the owner.
+type listener struct {
+	source     AudioSource
+	sampleRate int
+	vad        *config.VADConfig // nil for push-to-talk
+
+	mu      sync.Mutex
+	current *session
+	closed  bool
+	running sync.WaitGroup
+}
+
+// recognition is what a provider does with one session's audio. Both funcs
+// run on the session's owner goroutine.
+type recognition struct {
+	// audio receives each chunk in the order it was captured
+	audio func(pcm []byte)
+	// finish runs once the source has stopped and every chunk has been passed
+	// to audio. ctx is the session's context, which may have been cancelled.
+	finish func(ctx context.Context)
+}
+
+type session struct {
+	stop     chan struct{} // closed to end the session
+	stopOnce sync.Once
+	done     chan struct{} // closed once the recognition has finished
+
+	// cancel abandons the session. It is only called by close, as cancelling
+	// a finished session would cut off results still being delivered.
+	cancel context.CancelFunc
+}
+
+func (s *session) end() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// captureBuffer absorbs the capture thread running ahead of the recognizer
+const captureBuffer = 64
+
+func newListener(source AudioSource, sampleRate int) *listener {
+	return &listener{source: source, sampleRate: sampleRate}
+}
+
+// start begins a session. begin sets up the provider's recognition with the
+// session's context, and is only called once the listener is known to be free.
+func (l *listener) start(ctx context.Context, begin func(ctx context.Context) (*recognition, error)) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.closed {
+		return errors.New("recognizer is closed")
+	}
+	if l.current != nil {
+		return errors.New("already listening")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	s := &session{stop: make(chan struct{}), done: make(chan struct{}), cancel: cancel}
+
+	rec, err := begin(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	audio := make(chan []byte, captureBuffer)
+
+	// the sink runs on the capture thread, which must never block
+	sink := func(pcm []byte) {
+		select {
+		case audio <- pcm:
+		default:
+			logger.New().Warn("[sst] recognizer is behind, dropping audio")
+		}
+	}
+
+	if err := l.source.Start(watchSpeech(l.vad, l.sampleRate, sink, s.end), s.end); err != nil {
+		cancel()
+		rec.finish(ctx)
+		return err
+	}
+
+	l.current = s
+	l.running.Add(1)
+	go l.run(ctx, s, audio, rec)
+
+	return nil
+}
+
+// run owns a session from start to finish
+func (l *listener) run(ctx context.Context, s *session, audio chan []byte, rec *recognition) {
+	defer l.running.Done()
+	defer close(s.done)
+
+	for listening := true; listening; {
+		select {
+		case pcm := <-audio:
+			rec.audio(pcm)
+		case <-s.stop:
+			listening = false
+		case <-ctx.Done():
+			logger.New().Debug("[sst] session cancelled")
+			listening = false
+		}
+	}
+
+	// the sink is not called again once the source has stopped, so whatever
+	// is left in the buffer is the end of the recording
+	l.source.Stop()
+	for drained := false; !drained; {
+		select {
+		case pcm := <-audio:
+			rec.audio(pcm)
+		default:
+			drained = true
+		}
+	}
+
+	// free the listener before finishing, so the next session can start as
+	// soon as the detective has the results
+	l.mu.Lock()
+	l.current = nil
+	l.mu.Unlock()
+
+	rec.finish(ctx)
+}
+
+// stop ends the current session and waits for its recognition to finish
+func (l *listener) stop() {
+	l.mu.Lock()
+	s := l.current
+	l.mu.Unlock()
+
+	if s == nil {
+		return
+	}
+	s.end()
+	<-s.done
+}
+
+func (l *listener) listening() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.current != nil
+}
+
+// close abandons any session and releases the source. The session's channel
+// is closed by its provider as usual, so a reader simply sees it end.
+func (l *listener) close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	s := l.current
+	l.mu.Unlock()
+
+	if s != nil {
+		s.cancel()
+	}
+	l.running.Wait()
+
+	return l.source.Close()
+}
@@ -0,0 +1,351 @@
+package sst
+
+import (
+	"context"
+	"errors"
+	"gofigure/config"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// synthSource stands in for a capture device: its own goroutine delivers
+// generated audio until stopped or, if limit is set, until it has played
+// limit chunks and runs dry like a recording
+type synthSource struct {
+	limit  int
+	signal func(chunk int) []byte
+
+	mu     sync.Mutex
+	stop   chan struct{}
+	done   chan struct{}
+	starts int
+	stops  int
+	closed bool
+}
+
+func (s *synthSource) Start(sink func([]byte), onEnd func()) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return errors.New("source is closed")
+	}
+	if s.stop != nil {
+		return errors.New("source already started")
+	}
+
+	s.starts++
+	stop, done := make(chan struct{}), make(chan struct{})
+	s.stop, s.done = stop, done
+
+	go func() {
+		defer close(done)
+		for i := 0; s.limit == 0 || i < s.limit; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			sink(s.signal(i))
+			time.Sleep(time.Millisecond)
+		}
+		go onEnd()
+	}()
+
+	return nil
+}
+
+func (s *synthSource) Stop() {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	if stop != nil {
+		s.stops++
+	}
+	s.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (s *synthSource) Close() error {
+	s.Stop()
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *synthSource) counts() (starts, stops int, closed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.starts, s.stops, s.closed
+}
+
+// numbered marks every byte of a chunk with its position in the recording
+func numbered(chunk int) []byte {
+	pcm := make([]byte, 1024*2)
+	for i := range pcm {
+		pcm[i] = byte(chunk)
+	}
+	return pcm
+}
+
+// fakeRecognition records what a session hands to the recognizer
+type fakeRecognition struct {
+	chunks    [][]byte
+	finishes  int
+	cancelled bool
+	finished  chan struct{}
+}
+
+func newFakeRecognition() *fakeRecognition {
+	return &fakeRecognition{finished: make(chan struct{})}
+}
+
+func (f *fakeRecognition) begin(context.Context) (*recognition, error) {
+	return &recognition{
+		audio: func(pcm []byte) {
+			f.chunks = append(f.chunks, pcm)
+		},
+		finish: func(ctx context.Context) {
+			f.finishes++
+			f.cancelled = ctx.Err() != nil
+			close(f.finished)
+		},
+	}, nil
+}
+
+func (f *fakeRecognition) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-f.finished:
+	case <-time.After(5 * time.Second):
+		t.Fatal("session never finished")
+	}
+}
+
+func TestListenerDeliversEveryChunkInOrder(t *testing.T) {
+	source := &synthSource{limit: 20, signal: numbered}
+	l := newListener(source, testSampleRate)
+	rec := newFakeRecognition()
+
+	if err := l.start(context.Background(), rec.begin); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	rec.wait(t)
+
+	if len(rec.chunks) != 20 {
+		t.Fatalf("got %d chunks, want 20", len(rec.chunks))
+	}
+	for i, chunk := range rec.chunks {
+		if chunk[0] != byte(i) {
+			t.Fatalf("chunk %d arrived out of order", i)
+		}
+	}
+	if rec.finishes != 1 || rec.cancelled {
+		t.Errorf("finish called %d times, cancelled %t; want once, not cancelled", rec.finishes, rec.cancelled)
+	}
+	if l.listening() {
+		t.Error("still listening after the recording ran out")
+	}
+	if _, stops, _ := source.counts(); stops != 1 {
+		t.Errorf("source stopped %d times, want 1", stops)
+	}
+}
+
+func TestListenerStopWaitsForFinish(t *testing.T) {
+	source := &synthSource{signal: numbered}
+	l := newListener(source, testSampleRate)
+	rec := newFakeRecognition()
+
+	if err := l.start(context.Background(), rec.begin); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	l.stop()
+
+	// stop returning means the recognition has everything
+	if rec.finishes != 1 {
+		t.Fatalf("finish called %d times before stop returned, want 1", rec.finishes)
+	}
+	if len(rec.chunks) == 0 {
+		t.Error("no audio reached the recognizer")
+	}
+	if l.listening() {
+		t.Error("still listening after stop")
+	}
+}
+
+func TestListenerConcurrentStops(t *testing.T) {
+	source := &synthSource{signal: numbered}
+	l := newListener(source, testSampleRate)
+	rec := newFakeRecognition()
+
+	if err := l.start(context.Background(), rec.begin); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l.listening()
+			l.stop()
+		}()
+	}
+	wg.Wait()
+
+	rec.wait(t)
+	if rec.finishes != 1 {
+		t.Errorf("finish called %d times, want 1", rec.finishes)
+	}
+	if _, stops, _ := source.counts(); stops != 1 {
+		t.Errorf("source stopped %d times, want 1", stops)
+	}
+}
+
+func TestListenerCancelledContext(t *testing.T) {
+	source := &synthSource{signal: numbered}
+	l := newListener(source, testSampleRate)
+	rec := newFakeRecognition()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := l.start(ctx, rec.begin); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	rec.wait(t)
+	if !rec.cancelled {
+		t.Error("recognition was not told the session was cancelled")
+	}
+	if _, stops, _ := source.counts(); stops != 1 {
+		t.Errorf("source stopped %d times, want 1", stops)
+	}
+}
+
+func TestListenerRejectsSecondSession(t *testing.T) {
+	l := newListener(&synthSource{signal: numbered}, testSampleRate)
+	rec := newFakeRecognition()
+
+	if err := l.start(context.Background(), rec.begin); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer l.stop()
+
+	began := false
+	err := l.start(context.Background(), func(context.Context) (*recognition, error) {
+		began = true
+		return nil, nil
+	})
+	if err == nil {
+		t.Error("second session started while the first was running")
+	}
+	if began {
+		t.Error("second session's recognition was set up")
+	}
+}
+
+func TestListenerCloseAbandonsSession(t *testing.T) {
+	source := &synthSource{signal: numbered}
+	l := newListener(source, testSampleRate)
+	rec := newFakeRecognition()
+
+	if err := l.start(context.Background(), rec.begin); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	if err := l.close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	// close waits for the session, so it has already finished
+	if rec.finishes != 1 || !rec.cancelled {
+		t.Errorf("finish called %d times, cancelled %t; want once, cancelled", rec.finishes, rec.cancelled)
+	}
+	if _, _, closed := source.counts(); !closed {
+		t.Error("source was not closed")
+	}
+	if err := l.start(context.Background(), newFakeRecognition().begin); err == nil {
+		t.Error("session started after close")
+	}
+	if err := l.close(); err != nil {
+		t.Errorf("second close: %v", err)
+	}
+}
+
+func TestListenerVADEndsSession(t *testing.T) {
+	// half a second of speech, then silence for as long as it takes
+	source := &synthSource{signal: func(chunk int) []byte {
+		if chunk < 8 {
+			return tone(0.3)
+		}
+		return tone(0)
+	}}
+	l := newListener(source, testSampleRate)
+	l.vad = &config.VADConfig{Threshold: 0.02, SilenceMs: 300, MinSpeechMs: 100}
+	rec := newFakeRecognition()
+
+	if err := l.start(context.Background(), rec.begin); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	rec.wait(t)
+
+	if rec.cancelled {
+		t.Error("session was cancelled rather than ended by the pause")
+	}
+	// 8 chunks of speech and at least 300ms of silence
+	if len(rec.chunks) < 8+5 {
+		t.Errorf("session ended after %d chunks, before the pause was long enough", len(rec.chunks))
+	}
+}
+
+func TestWhisperTranscribesSession(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake whisper is a shell script")
+	}
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "whisper-cli")
+	script := "#!/bin/sh\necho ' [BLANK_AUDIO] Where were you at midnight?'\n"
+	if err := os.WriteFile(binary, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	model := filepath.Join(dir, "ggml-test.bin")
+	if err := os.WriteFile(model, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// a second of audio, long enough to be worth transcribing
+	source := &synthSource{limit: 16, signal: func(int) []byte { return tone(0.3) }}
+	w, err := NewWhisperSST(binary, model, "en-GB", source)
+	if err != nil {
+		t.Fatalf("NewWhisperSST: %v", err)
+	}
+	defer w.Close()
+
+	results, err := w.StartListening(context.Background())
+	if err != nil {
+		t.Fatalf("StartListening: %v", err)
+	}
+
+	var got []Result
+	for r := range results {
+		got = append(got, r)
+	}
+
+	// the first audio is marked by an empty interim result
+	if len(got) != 2 || got[0].Transcript != "" || got[0].IsFinal || got[1].Transcript != "Where were you at midnight?" || !got[1].IsFinal {
+		t.Errorf("got %+v, want the start of speech then a single final transcript", got)
+	}
+}
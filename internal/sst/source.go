@@ -0,0 +1,200 @@
+package sst
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"gofigure/internal/logger"
+	"io"
+	"os"
+	"time"
+)
+
+// AudioSource delivers 16-bit mono PCM to a recognizer, one listening session
+// at a time
+type AudioSource interface {
+	// Start begins a session, handing each chunk of audio to sink. A source
+	// that runs dry, such as a recording, calls onEnd on its own goroutine.
+	Start(sink func(pcm []byte), onEnd func()) error
+	// Stop ends the session. sink is not called again once Stop returns.
+	Stop()
+	// Close releases the source
+	Close() error
+}
+
+// ErrNoMoreAudio is returned by a FileSource once every recording has been played
+var ErrNoMoreAudio = errors.New("no more recorded audio")
+
+// fileChunkFrames matches the microphone's period size
+const fileChunkFrames = 1024
+
+// FileSource replays recordings in place of a microphone, one per listening
+// session, so voice mode can be driven by fixtures. Each recording is a WAV
+// file or raw 16-bit mono PCM at the recognizer's sample rate, and "-" reads
+// from stdin. Audio is delivered in real time, as a microphone would.
+type FileSource struct {
+	paths      []string
+	sampleRate int
+
+	next int
+	stop chan struct{} // closed by Stop
+	done chan struct{} // closed once the session's goroutine has returned
+}
+
+func NewFileSource(paths []string, sampleRate int) *FileSource {
+	return &FileSource{paths: paths, sampleRate: sampleRate}
+}
+
+// Start plays the next recording
+func (f *FileSource) Start(sink func(pcm []byte), onEnd func()) error {
+	if f.stop != nil {
+		return errors.New("audio source already started")
+	}
+	if f.next >= len(f.paths) {
+		return ErrNoMoreAudio
+	}
+
+	path := f.paths[f.next]
+	f.next++
+
+	audio, closer, err := openRecording(path, f.sampleRate)
+	if err != nil {
+		return err
+	}
+	logger.New().Debug(fmt.Sprintf("[audio-source] playing %s", path))
+
+	f.stop, f.done = make(chan struct{}), make(chan struct{})
+	go f.play(audio, closer, sink, onEnd, f.stop, f.done)
+
+	return nil
+}
+
+func (f *FileSource) play(audio io.Reader, closer io.Closer, sink func([]byte), onEnd func(), stop, done chan struct{}) {
+	defer close(done)
+	defer closer.Close()
+
+	period := time.Duration(fileChunkFrames) * time.Second / time.Duration(f.sampleRate)
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		chunk := make([]byte, fileChunkFrames*2)
+		n, err := io.ReadFull(audio, chunk)
+		if n -= n % 2; n > 0 {
+			sink(chunk[:n])
+		}
+
+		if err != nil {
+			if err != io.EOF && err != io.ErrUnexpectedEOF {
+				logger.New().WithError(err).Error("[audio-source] failed to read recording")
+			}
+			// Stop waits for this goroutine, so end the session from another
+			go onEnd()
+			return
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *FileSource) Stop() {
+	if f.stop == nil {
+		return
+	}
+	close(f.stop)
+	<-f.done
+	f.stop, f.done = nil, nil
+}
+
+func (f *FileSource) Close() error {
+	f.Stop()
+	return nil
+}
+
+// openRecording returns the PCM in a WAV file, or the whole file if it has no
+// WAV header
+func openRecording(path string, sampleRate int) (io.Reader, io.Closer, error) {
+	var file io.ReadCloser = io.NopCloser(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open recording: %w", err)
+		}
+		file = f
+	}
+
+	r := bufio.NewReader(file)
+	if magic, _ := r.Peek(4); string(magic) != "RIFF" {
+		return r, file, nil // raw PCM
+	}
+
+	audio, err := wavData(r, sampleRate)
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return audio, file, nil
+}
+
+// wavData checks a WAV file's format and returns a reader positioned at its audio
+func wavData(r io.Reader, sampleRate int) (io.Reader, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil || string(header[8:]) != "WAVE" {
+		return nil, errors.New("not a WAVE file")
+	}
+
+	hasFormat := false
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, errors.New("no audio data")
+		}
+		id, size := string(chunk[:4]), binary.LittleEndian.Uint32(chunk[4:])
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, errors.New("malformed fmt chunk")
+			}
+			format := make([]byte, size+size%2) // chunks are padded to an even size
+			if _, err := io.ReadFull(r, format); err != nil {
+				return nil, errors.New("malformed fmt chunk")
+			}
+
+			encoding := binary.LittleEndian.Uint16(format[0:])
+			channels := binary.LittleEndian.Uint16(format[2:])
+			rate := binary.LittleEndian.Uint32(format[4:])
+			bits := binary.LittleEndian.Uint16(format[14:])
+
+			if encoding != 1 && encoding != 0xFFFE { // PCM or WAVE_FORMAT_EXTENSIBLE
+				return nil, fmt.Errorf("unsupported WAV encoding %d, only PCM is supported", encoding)
+			}
+			if channels != 1 || bits != 16 {
+				return nil, fmt.Errorf("audio must be 16-bit mono, got %d-bit with %d channels", bits, channels)
+			}
+			if int(rate) != sampleRate {
+				return nil, fmt.Errorf("audio is %dHz, the recognizer expects %dHz", rate, sampleRate)
+			}
+			hasFormat = true
+
+		case "data":
+			if !hasFormat {
+				return nil, errors.New("data chunk before fmt chunk")
+			}
+			if size == 0 || size == 0xFFFFFFFF {
+				return r, nil // streamed WAV of unknown length
+			}
+			return io.LimitReader(r, int64(size)), nil
+
+		default:
+			if _, err := io.CopyN(io.Discard, r, int64(size+size%2)); err != nil {
+				return nil, errors.New("no audio data")
+			}
+		}
+	}
+}
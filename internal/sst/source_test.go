@@ -0,0 +1,177 @@
+package sst
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSampleRate = 16000
+
+// tone is a chunk of 64ms of 440Hz sine at the given amplitude between 0 and 1
+func tone(amplitude float64) []byte {
+	pcm := make([]byte, 1024*2)
+	for i := 0; i < 1024; i++ {
+		sample := amplitude * math.MaxInt16 * math.Sin(2*math.Pi*440*float64(i)/testSampleRate)
+		binary.LittleEndian.PutUint16(pcm[i*2:], uint16(int16(sample)))
+	}
+	return pcm
+}
+
+// wavChunk is a RIFF chunk, padded to an even size
+func wavChunk(id string, body []byte) []byte {
+	chunk := append([]byte(id), binary.LittleEndian.AppendUint32(nil, uint32(len(body)))...)
+	chunk = append(chunk, body...)
+	if len(body)%2 == 1 {
+		chunk = append(chunk, 0)
+	}
+	return chunk
+}
+
+func fmtChunk(encoding, channels uint16, rate uint32, bits uint16) []byte {
+	body := binary.LittleEndian.AppendUint16(nil, encoding)
+	body = binary.LittleEndian.AppendUint16(body, channels)
+	body = binary.LittleEndian.AppendUint32(body, rate)
+	body = binary.LittleEndian.AppendUint32(body, rate*uint32(channels*bits/8))
+	body = binary.LittleEndian.AppendUint16(body, channels*bits/8)
+	body = binary.LittleEndian.AppendUint16(body, bits)
+	return wavChunk("fmt ", body)
+}
+
+func riff(chunks ...[]byte) []byte {
+	body := []byte("WAVE")
+	for _, c := range chunks {
+		body = append(body, c...)
+	}
+	return wavChunk("RIFF", body)
+}
+
+// play runs one session of a source to the end of its recording
+func play(t *testing.T, source AudioSource) []byte {
+	t.Helper()
+
+	var got bytes.Buffer
+	ended := make(chan struct{})
+	if err := source.Start(func(pcm []byte) { got.Write(pcm) }, func() { close(ended) }); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	select {
+	case <-ended:
+	case <-time.After(5 * time.Second):
+		t.Fatal("recording never ended")
+	}
+	source.Stop()
+	return got.Bytes()
+}
+
+func TestFileSourcePlaysRecordingsInTurn(t *testing.T) {
+	dir := t.TempDir()
+	wav := filepath.Join(dir, "question.wav")
+	raw := filepath.Join(dir, "question.pcm")
+	if err := os.WriteFile(wav, encodeWAV(tone(0.3), testSampleRate), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(raw, append(tone(0.3), tone(0.3)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewFileSource([]string{wav, raw}, testSampleRate)
+
+	if got := play(t, source); !bytes.Equal(got, tone(0.3)) {
+		t.Errorf("WAV recording played %d bytes, want its %d bytes of audio", len(got), len(tone(0.3)))
+	}
+	if got := play(t, source); len(got) != 2048*2 {
+		t.Errorf("raw recording played %d bytes, want %d", len(got), 2048*2)
+	}
+
+	err := source.Start(func([]byte) {}, func() {})
+	if !errors.Is(err, ErrNoMoreAudio) {
+		t.Errorf("got %v after the last recording, want ErrNoMoreAudio", err)
+	}
+}
+
+func TestFileSourceStopEndsPlayback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "long.pcm")
+	if err := os.WriteFile(path, bytes.Repeat(tone(0.3), 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewFileSource([]string{path}, testSampleRate)
+	chunks := 0
+	if err := source.Start(func([]byte) { chunks++ }, func() { t.Error("stopped recording reported its end") }); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	source.Stop()
+
+	// sink is not called once Stop has returned
+	after := chunks
+	time.Sleep(100 * time.Millisecond)
+	if chunks != after || chunks >= 100 {
+		t.Errorf("played %d chunks then %d after Stop, want playback to stop", after, chunks)
+	}
+}
+
+func TestWavData(t *testing.T) {
+	audio := []byte{1, 2, 3, 4}
+
+	tests := []struct {
+		name    string
+		wav     []byte
+		want    []byte
+		wantErr string
+	}{
+		{name: "pcm", wav: riff(fmtChunk(1, 1, testSampleRate, 16), wavChunk("data", audio)), want: audio},
+		{name: "extensible", wav: riff(fmtChunk(0xFFFE, 1, testSampleRate, 16), wavChunk("data", audio)), want: audio},
+		{name: "skips other chunks", wav: riff(wavChunk("LIST", []byte("odd")), fmtChunk(1, 1, testSampleRate, 16), wavChunk("data", audio)), want: audio},
+		{name: "stops at the end of the data", wav: riff(fmtChunk(1, 1, testSampleRate, 16), wavChunk("data", audio), wavChunk("junk", []byte{9, 9})), want: audio},
+		{name: "streamed", wav: riff(fmtChunk(1, 1, testSampleRate, 16), []byte("data\xff\xff\xff\xff\x01\x02")), want: []byte{1, 2}},
+		{name: "not a wave", wav: wavChunk("RIFF", []byte("AVI ")), wantErr: "not a WAVE"},
+		{name: "compressed", wav: riff(fmtChunk(3, 1, testSampleRate, 16), wavChunk("data", audio)), wantErr: "only PCM"},
+		{name: "stereo", wav: riff(fmtChunk(1, 2, testSampleRate, 16), wavChunk("data", audio)), wantErr: "16-bit mono"},
+		{name: "8 bit", wav: riff(fmtChunk(1, 1, testSampleRate, 8), wavChunk("data", audio)), wantErr: "16-bit mono"},
+		{name: "wrong rate", wav: riff(fmtChunk(1, 1, 44100, 16), wavChunk("data", audio)), wantErr: "44100Hz"},
+		{name: "data before fmt", wav: riff(wavChunk("data", audio), fmtChunk(1, 1, testSampleRate, 16)), wantErr: "before fmt"},
+		{name: "no data", wav: riff(fmtChunk(1, 1, testSampleRate, 16)), wantErr: "no audio data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := wavData(bytes.NewReader(tt.wav), testSampleRate)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("wavData: %v", err)
+			}
+
+			got, _ := io.ReadAll(r)
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("got audio %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenRecordingRejectsWrongSampleRate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cd.wav")
+	if err := os.WriteFile(path, encodeWAV(tone(0.3), 44100), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := NewFileSource([]string{path}, testSampleRate).Start(func([]byte) {}, func() {})
+	if err == nil {
+		t.Fatal("44.1kHz recording accepted by a 16kHz recognizer")
+	}
+}
@@ -0,0 +1,141 @@
+package sst
+
+import (
+	"encoding/binary"
+	"gofigure/config"
+	"math"
+	"sync"
+	"time"
+)
+
+type vadEvent int
+
+const (
+	vadNone vadEvent = iota
+	vadSpeechStart
+	vadSpeechEnd
+)
+
+// vad is an energy based voice activity detector. Audio counts as speech once
+// its level has stayed above the threshold for the minimum speech time, and
+// the speech is over once it has been quiet for the trailing silence.
+type vad struct {
+	threshold      float64
+	minSpeech      time.Duration
+	silence        time.Duration
+	bytesPerSecond int
+
+	speaking bool
+	voiced   time.Duration
+	quiet    time.Duration
+}
+
+func newVAD(cfg *config.VADConfig, sampleRate int) *vad {
+	return &vad{
+		threshold:      cfg.Threshold,
+		minSpeech:      time.Duration(cfg.MinSpeechMs) * time.Millisecond,
+		silence:        time.Duration(cfg.SilenceMs) * time.Millisecond,
+		bytesPerSecond: sampleRate * 2, // 16-bit mono
+	}
+}
+
+// process classifies the next chunk of 16-bit PCM
+func (v *vad) process(pcm []byte) vadEvent {
+	dur := v.duration(pcm)
+
+	switch {
+	case level(pcm) >= v.threshold:
+		v.voiced += dur
+		v.quiet = 0
+		if !v.speaking && v.voiced >= v.minSpeech {
+			v.speaking = true
+			return vadSpeechStart
+		}
+
+	case v.speaking:
+		v.quiet += dur
+		if v.quiet >= v.silence {
+			v.speaking = false
+			v.voiced, v.quiet = 0, 0
+			return vadSpeechEnd
+		}
+
+	default:
+		v.voiced = 0 // a click or a cough, not speech
+	}
+
+	return vadNone
+}
+
+// duration is how long a chunk of 16-bit PCM plays for
+func (v *vad) duration(pcm []byte) time.Duration {
+	return time.Duration(len(pcm)) * time.Second / time.Duration(v.bytesPerSecond)
+}
+
+// level is the RMS of 16-bit PCM, scaled to 0..1
+func level(pcm []byte) float64 {
+	samples := len(pcm) / 2
+	if samples == 0 {
+		return 0
+	}
+
+	var sum float64
+	for i := 0; i+1 < len(pcm); i += 2 {
+		s := float64(int16(binary.LittleEndian.Uint16(pcm[i:]))) / math.MaxInt16
+		sum += s * s
+	}
+	return math.Sqrt(sum / float64(samples))
+}
+
+// preRoll is how much of the audio from before speech was detected is passed
+// on with it, so the start of the first word isn't clipped
+const preRoll = 300 * time.Millisecond
+
+// watchSpeech wraps a capture sink with a fresh detector. Nothing reaches the
+// sink until speech starts, when the audio that led up to it is passed on
+// first. onSpeechEnd is called once, on its own goroutine, when the first
+// utterance has been followed by the trailing silence; stopping the device
+// from the audio thread would deadlock.
+func watchSpeech(cfg *config.VADConfig, sampleRate int, sink func([]byte), onSpeechEnd func()) func([]byte) {
+	if cfg == nil {
+		return sink
+	}
+
+	detector := newVAD(cfg, sampleRate)
+	var once sync.Once
+
+	// the chunks that made speech start, plus the pre-roll before them
+	keep := detector.minSpeech + preRoll
+	var waiting [][]byte
+	var waited time.Duration
+	heard := false
+
+	return func(pcm []byte) {
+		event := detector.process(pcm)
+
+		switch {
+		case heard:
+			sink(pcm)
+
+		case event == vadSpeechStart:
+			heard = true
+			for _, chunk := range waiting {
+				sink(chunk)
+			}
+			waiting = nil
+			sink(pcm)
+
+		default:
+			waiting = append(waiting, pcm)
+			waited += detector.duration(pcm)
+			for len(waiting) > 1 && waited-detector.duration(waiting[0]) >= keep {
+				waited -= detector.duration(waiting[0])
+				waiting = waiting[1:]
+			}
+		}
+
+		if event == vadSpeechEnd {
+			once.Do(func() { go onSpeechEnd() })
+		}
+	}
+}
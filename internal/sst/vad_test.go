@@ -0,0 +1,149 @@
+package sst
+
+import (
+	"bytes"
+	"encoding/binary"
+	"gofigure/config"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLevel(t *testing.T) {
+	square := make([]byte, 64)
+	for i := 0; i < len(square); i += 2 {
+		sample := int16(math.MaxInt16)
+		if i%4 == 0 {
+			sample = -math.MaxInt16
+		}
+		binary.LittleEndian.PutUint16(square[i:], uint16(sample))
+	}
+
+	tests := []struct {
+		name string
+		pcm  []byte
+		want float64
+	}{
+		{"empty", nil, 0},
+		{"one byte", []byte{0xff}, 0},
+		{"silence", make([]byte, 2048), 0},
+		{"full scale square", square, 1},
+		{"half scale sine", tone(0.5), 0.5 / math.Sqrt2},
+		{"quiet sine", tone(0.01), 0.01 / math.Sqrt2},
+		{"trailing odd byte ignored", append(tone(0.5), 0x7f), 0.5 / math.Sqrt2},
+	}
+
+	for _, tt := range tests {
+		if got := level(tt.pcm); math.Abs(got-tt.want) > 0.002 {
+			t.Errorf("%s: level = %.4f, want %.4f", tt.name, got, tt.want)
+		}
+	}
+}
+
+// testVAD is 0.1 loud, and needs 100ms of speech and 200ms of silence. Each
+// tone chunk is 64ms.
+var testVAD = config.VADConfig{Threshold: 0.1, MinSpeechMs: 100, SilenceMs: 200}
+
+func TestVADProcess(t *testing.T) {
+	const (
+		_ = iota
+		loud
+		quiet
+	)
+
+	tests := []struct {
+		name   string
+		cfg    config.VADConfig
+		chunks []int
+		start  int // chunk the speech starts on, -1 for never
+		end    int // chunk the speech ends on, -1 for never
+	}{
+		{name: "silence", cfg: testVAD, chunks: []int{quiet, quiet, quiet}, start: -1, end: -1},
+		{name: "too short to be speech", cfg: testVAD, chunks: []int{loud, quiet, loud, quiet}, start: -1, end: -1},
+		{name: "speech", cfg: testVAD, chunks: []int{quiet, loud, loud, loud}, start: 2, end: -1},
+		{name: "end of speech", cfg: testVAD, chunks: []int{loud, loud, quiet, quiet, quiet, quiet, quiet}, start: 1, end: 5},
+		{name: "pause mid sentence", cfg: testVAD, chunks: []int{loud, loud, quiet, quiet, loud, quiet, quiet, quiet, quiet}, start: 1, end: 8},
+		{name: "no minimum", cfg: config.VADConfig{Threshold: 0.1, SilenceMs: 100}, chunks: []int{loud, quiet, quiet}, start: 0, end: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newVAD(&tt.cfg, testSampleRate)
+			start, end := -1, -1
+
+			for i, c := range tt.chunks {
+				pcm := tone(0.01)
+				if c == loud {
+					pcm = tone(0.5)
+				}
+
+				switch v.process(pcm) {
+				case vadSpeechStart:
+					if start >= 0 {
+						t.Errorf("speech started again on chunk %d", i)
+					}
+					start = i
+				case vadSpeechEnd:
+					if end >= 0 {
+						t.Errorf("speech ended again on chunk %d", i)
+					}
+					end = i
+				}
+			}
+
+			if start != tt.start || end != tt.end {
+				t.Errorf("speech from chunk %d to %d, want %d to %d", start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestWatchSpeechWaitsForSpeech(t *testing.T) {
+	var heard [][]byte
+	ended := make(chan struct{})
+	sink := watchSpeech(&testVAD, testSampleRate, func(pcm []byte) { heard = append(heard, pcm) }, func() { close(ended) })
+
+	for i := 0; i < 20; i++ {
+		sink(tone(0.01))
+	}
+	if len(heard) != 0 {
+		t.Fatalf("passed on %d chunks of silence before anyone spoke", len(heard))
+	}
+
+	first := tone(0.4) // not enough on its own to start speech
+	sink(first)
+	sink(tone(0.5))
+	for i := 0; i < 4; i++ {
+		sink(tone(0.01))
+	}
+
+	// 100ms of speech and 300ms of pre-roll before it are kept: the six
+	// quiet chunks and the first loud one, then the rest of the utterance
+	if len(heard) != 12 {
+		t.Fatalf("passed on %d chunks, want 12", len(heard))
+	}
+	if !bytes.Equal(heard[6], first) {
+		t.Error("pre-roll is out of order")
+	}
+	if preroll := time.Duration(len(bytes.Join(heard[:6], nil))) * time.Second / (testSampleRate * 2); preroll < preRoll {
+		t.Errorf("kept %s of audio before speech, want at least %s", preroll, preRoll)
+	}
+
+	select {
+	case <-ended:
+	case <-time.After(time.Second):
+		t.Fatal("end of speech never reported")
+	}
+}
+
+func TestWatchSpeechWithoutVAD(t *testing.T) {
+	var heard int
+	sink := watchSpeech(nil, testSampleRate, func([]byte) { heard++ }, func() { t.Error("push to talk ended on silence") })
+
+	for i := 0; i < 10; i++ {
+		sink(tone(0.01))
+	}
+	if heard != 10 {
+		t.Errorf("passed on %d of 10 chunks, want all of them", heard)
+	}
+}
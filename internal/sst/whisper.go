@@ -0,0 +1,224 @@
+package sst
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"gofigure/config"
+	"gofigure/internal/logger"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// whisper.cpp only accepts 16kHz audio
+const whisperSampleRate = 16000
+
+// nonSpeech matches the markers whisper writes for silence and noise, e.g. [BLANK_AUDIO] or (wind blowing)
+var nonSpeech = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)`)
+
+// WhisperSST transcribes speech offline by running whisper.cpp over each
+// recording once the detective stops talking
+type WhisperSST struct {
+	binary   string
+	model    string
+	language string
+
+	listener *listener
+
+	mu     sync.Mutex
+	prompt string // phrase hints, given to whisper as the preceding text
+}
+
+// whisper only keeps a couple of hundred tokens of prompt, and the hints are
+// ordered most important first
+const maxPromptLength = 800
+
+// NewWhisperSST checks the whisper.cpp binary and model exist. languageCode is
+// a BCP-47 code such as en-GB; whisper only uses the language part. source
+// must deliver 16kHz 16-bit mono PCM and is owned by the recognizer.
+func NewWhisperSST(binary, model, languageCode string, source AudioSource) (*WhisperSST, error) {
+	if model == "" {
+		return nil, fmt.Errorf("whisper model path is required (sst.whisper_model)")
+	}
+	if _, err := os.Stat(model); err != nil {
+		return nil, fmt.Errorf("whisper model not found: %w", err)
+	}
+
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("whisper binary %q not found: %w", binary, err)
+	}
+
+	language, _, _ := strings.Cut(languageCode, "-")
+	if language == "" {
+		language = "auto"
+	}
+
+	return &WhisperSST{
+		binary:   path,
+		model:    model,
+		language: strings.ToLower(language),
+		listener: newListener(source, whisperSampleRate),
+	}, nil
+}
+
+// WithVAD transcribes each recording by itself once the detective has
+// finished speaking. A nil config keeps push-to-talk.
+func (w *WhisperSST) WithVAD(cfg *config.VADConfig) *WhisperSST {
+	w.listener.vad = cfg
+	return w
+}
+
+// SetPhraseHints primes whisper with the hints as if they had just been said,
+// which nudges it towards their spelling
+func (w *WhisperSST) SetPhraseHints(phrases []string) {
+	prompt := strings.Join(phrases, ", ")
+	if len(prompt) > maxPromptLength {
+		prompt = prompt[:maxPromptLength]
+		if i := strings.LastIndex(prompt, ", "); i > 0 {
+			prompt = prompt[:i]
+		}
+	}
+
+	w.mu.Lock()
+	w.prompt = prompt
+	w.mu.Unlock()
+}
+
+// StartListening records until StopListening, the end of speech in vad mode
+// or ctx is done. The recording is then transcribed and the final result sent
+// before the channel is closed; a cancelled recording is thrown away. Whisper
+// has nothing to show while recording, so an empty interim result marks the
+// first audio, which in vad mode is the start of speech.
+func (w *WhisperSST) StartListening(ctx context.Context) (<-chan Result, error) {
+	logger.New().Debug("[whisper-sst] start-listening called")
+
+	w.mu.Lock()
+	prompt := w.prompt
+	w.mu.Unlock()
+
+	results := make(chan Result, 2) // the start of speech and the transcript
+
+	err := w.listener.start(ctx, func(context.Context) (*recognition, error) {
+		var pcm []byte // only touched by the session's owner goroutine
+		return &recognition{
+			audio: func(chunk []byte) {
+				if pcm == nil {
+					select {
+					case results <- Result{}:
+					default:
+					}
+				}
+				pcm = append(pcm, chunk...)
+			},
+			finish: func(ctx context.Context) {
+				defer close(results)
+				if ctx.Err() != nil {
+					logger.New().Debug("[whisper-sst] recording cancelled")
+					return
+				}
+				w.finish(pcm, prompt, results)
+			},
+		}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// finish transcribes a recording and sends the result
+func (w *WhisperSST) finish(pcm []byte, prompt string, results chan<- Result) {
+	// anything under half a second is a stray key press, not a question
+	if len(pcm) < whisperSampleRate {
+		logger.New().Debug(fmt.Sprintf("[whisper-sst] recording too short to transcribe: %d bytes", len(pcm)))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	transcript, err := w.transcribe(ctx, pcm, prompt)
+	if err != nil {
+		logger.New().WithError(err).Error("[whisper-sst] failed to transcribe recording")
+		return
+	}
+	if transcript == "" {
+		logger.New().Debug("[whisper-sst] no speech recognised")
+		return
+	}
+
+	results <- Result{Transcript: transcript, IsFinal: true}
+}
+
+// StopListening ends the recording and transcribes it, so the final result is
+// waiting on the channel by the time this returns
+func (w *WhisperSST) StopListening() error {
+	logger.New().Debug("[whisper-sst] stop-listening called")
+	w.listener.stop()
+	return nil
+}
+
+// transcribe writes the recording to a temporary WAV file and runs whisper.cpp over it
+func (w *WhisperSST) transcribe(ctx context.Context, pcm []byte, prompt string) (string, error) {
+	f, err := os.CreateTemp("", "gofigure-*.wav")
+	if err != nil {
+		return "", fmt.Errorf("failed to create recording file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(encodeWAV(pcm, whisperSampleRate))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write recording: %w", err)
+	}
+
+	logger.New().Debug(fmt.Sprintf("[whisper-sst] transcribing %d bytes with %s", len(pcm), w.model))
+	start := time.Now()
+
+	args := []string{
+		"-m", w.model,
+		"-f", f.Name(),
+		"-l", w.language,
+		"-nt", // no timestamps
+		"-np", // no progress or system info
+	}
+	if prompt != "" {
+		args = append(args, "--prompt", prompt)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, w.binary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("whisper failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	transcript := nonSpeech.ReplaceAllString(stdout.String(), " ")
+	transcript = strings.Join(strings.Fields(transcript), " ")
+
+	logger.New().Debug(fmt.Sprintf("[whisper-sst] transcript: '%s' (took %s)", transcript, time.Since(start).Round(time.Millisecond)))
+	return transcript, nil
+}
+
+func (w *WhisperSST) IsListening() bool {
+	return w.listener.listening()
+}
+
+func (w *WhisperSST) Provider() string {
+	return "whisper"
+}
+
+// Close abandons any recording and releases the audio source
+func (w *WhisperSST) Close() error {
+	return w.listener.close()
+}
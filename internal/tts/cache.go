@@ -0,0 +1,159 @@
+package tts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"gofigure/internal/game/audio"
+	"gofigure/internal/logger"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedTts keeps every line an engine renders on disk, keyed by what was
+// said and how, so replaying a mystery does not synthesize the same speech again
+type CachedTts struct {
+	Tts
+	synth    Synthesizer
+	dir      string
+	maxBytes int64
+
+	mu sync.Mutex // serialises eviction
+}
+
+// NewCachedTts wraps t with a cache in dir holding at most maxBytes of audio.
+// Engines that can't synthesize without playing are returned unwrapped.
+func NewCachedTts(t Tts, dir string, maxBytes int64) (Tts, error) {
+	synth, ok := t.(Synthesizer)
+	if !ok {
+		logger.New().Debug(fmt.Sprintf("[tts-cache] %s engine can't be cached", t.Name()))
+		return t, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create tts cache directory: %w", err)
+	}
+
+	return &CachedTts{Tts: t, synth: synth, dir: dir, maxBytes: maxBytes}, nil
+}
+
+func (c *CachedTts) Speak(ctx context.Context, line Line) error {
+	data, err := c.Synthesize(ctx, line)
+	if err != nil {
+		return err
+	}
+	return audio.PlayWAV(ctx, data)
+}
+
+// Synthesize returns the cached recording, rendering and storing it on a miss
+func (c *CachedTts) Synthesize(ctx context.Context, line Line) ([]byte, error) {
+	path := filepath.Join(c.dir, c.key(line)+".wav")
+
+	if data, err := os.ReadFile(path); err == nil {
+		logger.New().Debug(fmt.Sprintf("[tts-cache] hit %s", filepath.Base(path)))
+
+		// eviction is least recently used, so reading counts as use
+		now := time.Now()
+		if err := os.Chtimes(path, now, now); err != nil {
+			logger.New().Warn(fmt.Sprintf("[tts-cache] failed to mark %s as used: %v", filepath.Base(path), err))
+		}
+		return data, nil
+	}
+
+	data, err := c.synth.Synthesize(ctx, line)
+	if err != nil {
+		return nil, err
+	}
+
+	// a recording bigger than the whole cache would only evict itself
+	if c.maxBytes > 0 && int64(len(data)) > c.maxBytes {
+		logger.New().Debug(fmt.Sprintf("[tts-cache] %d bytes is too big to cache", len(data)))
+		return data, nil
+	}
+
+	if err := c.store(path, data); err != nil {
+		logger.New().Warn(fmt.Sprintf("[tts-cache] failed to store speech: %v", err))
+	}
+	return data, nil
+}
+
+// key addresses a recording by everything that changes how it sounds,
+// including a character's own prosody for the emotion. The voice is keyed as
+// resolved, so changing an engine's default voice doesn't replay the old one.
+func (c *CachedTts) key(line Line) string {
+	prosody, _ := line.prosody()
+	model := line.Model
+	if v, ok := c.Tts.(voicer); ok {
+		model = v.voice(model)
+	}
+
+	h := sha256.New()
+	for _, part := range []string{c.Name(), model, line.Text, line.Emotions, fmt.Sprintf("%+v", prosody)} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *CachedTts) store(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return c.evict()
+}
+
+// evict removes the least recently used recordings until the cache fits maxBytes
+func (c *CachedTts) evict() error {
+	if c.maxBytes <= 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	var files []os.FileInfo
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".wav") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, info)
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, f := range files {
+		if total <= c.maxBytes {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.dir, f.Name())); err != nil {
+			return err
+		}
+		total -= f.Size()
+		logger.New().Debug(fmt.Sprintf("[tts-cache] evicted %s", f.Name()))
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// recorder synthesizes each line as its own text, counting the calls
+type recorder struct {
+	calls int
+}
+
+func (r *recorder) Speak(context.Context, Line) error { return nil }
+func (r *recorder) Name() string                      { return "recorder" }
+
+func (r *recorder) Synthesize(_ context.Context, line Line) ([]byte, error) {
+	r.calls++
+	return []byte(line.Text), nil
+}
+
+func newTestCache(t *testing.T, engine Tts, maxBytes int64) *CachedTts {
+	t.Helper()
+
+	c, err := NewCachedTts(engine, t.TempDir(), maxBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.(*CachedTts)
+}
+
+func TestCacheKey(t *testing.T) {
+	c := newTestCache(t, &recorder{}, 0)
+	base := c.key(Line{Text: "Hello.", Emotions: "calm", Model: "voice-a"})
+
+	if c.key(Line{Text: "Hello.", Emotions: "calm", Model: "voice-a"}) != base {
+		t.Error("the same line got two keys")
+	}
+
+	different := map[string]string{
+		"text":    c.key(Line{Text: "Goodbye.", Emotions: "calm", Model: "voice-a"}),
+		"emotion": c.key(Line{Text: "Hello.", Emotions: "angry", Model: "voice-a"}),
+		"voice":   c.key(Line{Text: "Hello.", Emotions: "calm", Model: "voice-b"}),
+		"prosody": c.key(Line{Text: "Hello.", Emotions: "calm", Model: "voice-a", Prosody: map[string]Prosody{"calm": {Rate: 0.5}}}),
+		"engine":  newTestCache(t, &PiperTTS{}, 0).key(Line{Text: "Hello.", Emotions: "calm", Model: "voice-a"}),
+	}
+	for what, key := range different {
+		if key == base {
+			t.Errorf("changing the %s kept the same key", what)
+		}
+	}
+
+	// field boundaries are kept, so text can't run into the emotion
+	if c.key(Line{Text: "ab", Emotions: "c"}) == c.key(Line{Text: "a", Emotions: "bc"}) {
+		t.Error("keys collide across fields")
+	}
+}
+
+func TestCacheKeyUsesTheResolvedVoice(t *testing.T) {
+	alan := newTestCache(t, &PiperTTS{defaultVoice: "en_GB-alan-medium"}, 0)
+	amy := newTestCache(t, &PiperTTS{defaultVoice: "en_US-amy-medium"}, 0)
+
+	if alan.key(Line{Text: "Hello.", Emotions: "calm"}) == amy.key(Line{Text: "Hello.", Emotions: "calm"}) {
+		t.Error("changing tts.piper.voice kept the same key for characters without a voice")
+	}
+	if alan.key(Line{Text: "Hello.", Emotions: "calm"}) != alan.key(Line{Text: "Hello.", Emotions: "calm", Model: "en_GB-alan-medium"}) {
+		t.Error("the default voice and the same voice named outright got different keys")
+	}
+
+	google := newTestCache(t, &GoogleTTS{}, 0)
+	if google.key(Line{Text: "Hello.", Emotions: "calm"}) != google.key(Line{Text: "Hello.", Emotions: "calm", Model: "en-GB-Chirp3-HD-Charon"}) {
+		t.Error("google's default voice and the same voice named outright got different keys")
+	}
+}
+
+func TestCacheSynthesizesOnce(t *testing.T) {
+	engine := &recorder{}
+	c := newTestCache(t, engine, 0)
+
+	for i := 0; i < 3; i++ {
+		data, err := c.Synthesize(context.Background(), Line{Text: "Hello.", Emotions: "calm"})
+		if err != nil || !bytes.Equal(data, []byte("Hello.")) {
+			t.Fatalf("got (%q, %v)", data, err)
+		}
+	}
+	if engine.calls != 1 {
+		t.Errorf("engine synthesized the line %d times, want 1", engine.calls)
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	engine := &recorder{}
+	c := newTestCache(t, engine, 25) // room for two 10 byte lines
+	ctx := context.Background()
+
+	age := func(text string, ago time.Duration) {
+		then := time.Now().Add(-ago)
+		if err := os.Chtimes(filepath.Join(c.dir, c.key(Line{Text: text})+".wav"), then, then); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cached := func(text string) bool {
+		_, err := os.Stat(filepath.Join(c.dir, c.key(Line{Text: text})+".wav"))
+		return err == nil
+	}
+
+	c.Synthesize(ctx, Line{Text: "first line"})
+	age("first line", 3*time.Hour)
+	c.Synthesize(ctx, Line{Text: "secondline"})
+	age("secondline", 2*time.Hour)
+
+	// replaying the first line makes it the most recently used
+	c.Synthesize(ctx, Line{Text: "first line"})
+	c.Synthesize(ctx, Line{Text: "third line"})
+
+	if !cached("first line") || cached("secondline") || !cached("third line") {
+		t.Errorf("cached first %v, second %v, third %v; want the second evicted", cached("first line"), cached("secondline"), cached("third line"))
+	}
+	if engine.calls != 3 {
+		t.Errorf("engine synthesized %d lines, want 3", engine.calls)
+	}
+}
+
+func TestCacheSkipsLinesBiggerThanTheCache(t *testing.T) {
+	engine := &recorder{}
+	c := newTestCache(t, engine, 15)
+	ctx := context.Background()
+
+	c.Synthesize(ctx, Line{Text: "short line"})
+	long := Line{Text: "a line too long to keep"}
+	for i := 0; i < 2; i++ {
+		if data, err := c.Synthesize(ctx, long); err != nil || string(data) != long.Text {
+			t.Fatalf("got (%q, %v)", data, err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(c.dir, c.key(Line{Text: "short line"})+".wav")); err != nil {
+		t.Errorf("a line too big to cache evicted the rest: %v", err)
+	}
+	if engine.calls != 3 {
+		t.Errorf("engine synthesized %d lines, want 3", engine.calls)
+	}
+}
+
+func TestCacheSkipsEnginesThatOnlyPlay(t *testing.T) {
+	dummy := NewDummyTts()
+	got, err := NewCachedTts(dummy, filepath.Join(t.TempDir(), "unused"), 0)
+	if err != nil || got != Tts(dummy) {
+		t.Errorf("got (%v, %v), want the engine unwrapped", got, err)
+	}
+}
@@ -0,0 +1,140 @@
+package tts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gofigure/config"
+	"gofigure/internal/logger"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Factory builds a tts engine from the tts config section
+type Factory func(ctx context.Context, cfg *config.TtsConfig) (Tts, error)
+
+type registration struct {
+	name    string
+	factory Factory
+
+	// available guesses whether the factory would succeed, without creating
+	// a client or touching the disk
+	available func(cfg *config.TtsConfig) error
+}
+
+// registry holds every engine that tts.type, tts.fallback and mystery files may name
+var registry = []registration{
+	{"google", func(ctx context.Context, _ *config.TtsConfig) (Tts, error) {
+		return NewGoogleTTS(ctx)
+	}, func(_ *config.TtsConfig) error {
+		return googleCredentials()
+	}},
+	{"piper", func(_ context.Context, cfg *config.TtsConfig) (Tts, error) {
+		return NewPiperTTS(cfg.Piper.Binary, cfg.Piper.VoicesDir, cfg.Piper.Voice)
+	}, func(cfg *config.TtsConfig) error {
+		_, err := exec.LookPath(cfg.Piper.Binary)
+		return err
+	}},
+}
+
+// Engines lists the registered engine names
+func Engines() []string {
+	names := make([]string, len(registry))
+	for i, r := range registry {
+		names[i] = r.name
+	}
+	return names
+}
+
+// NewTts creates the engine named by tts.type, trying each tts.fallback engine
+// in order if it can't be created. A disabled config gives the dummy engine.
+func NewTts(ctx context.Context, cfg *config.TtsConfig) (Tts, error) {
+	if !cfg.Enabled {
+		return NewDummyTts(), nil
+	}
+
+	var errs []error
+	for _, name := range append([]string{cfg.Type}, cfg.Fallback...) {
+		t, err := newEngine(ctx, cfg, name)
+		if err == nil {
+			logger.New().Debug(fmt.Sprintf("%s tts client created", name))
+			return withCache(t, cfg.Cache), nil
+		}
+
+		logger.New().Warn(fmt.Sprintf("failed to create %s tts engine: %v", name, err))
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+	}
+
+	return nil, fmt.Errorf("no tts engine could be created: %w", errors.Join(errs...))
+}
+
+// ActiveEngine names the engine NewTts would most likely pick, without creating
+// any, so reporting it has no side effects
+func ActiveEngine(cfg *config.TtsConfig) (string, error) {
+	if !cfg.Enabled {
+		return NewDummyTts().Name(), nil
+	}
+
+	var errs []error
+	for _, name := range append([]string{cfg.Type}, cfg.Fallback...) {
+		r, ok := lookup(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unsupported tts engine", name))
+			continue
+		}
+		if err := r.available(cfg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		return name, nil
+	}
+	return "", errors.Join(errs...)
+}
+
+func lookup(name string) (registration, bool) {
+	for _, r := range registry {
+		if r.name == name {
+			return r, true
+		}
+	}
+	return registration{}, false
+}
+
+// googleCredentials checks application default credentials can be found,
+// either through GOOGLE_APPLICATION_CREDENTIALS or gcloud's well known file
+func googleCredentials() error {
+	if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		_, err := os.Stat(path)
+		return err
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(dir, "gcloud", "application_default_credentials.json")); err != nil {
+		return errors.New("no google application default credentials found")
+	}
+	return nil
+}
+
+func newEngine(ctx context.Context, cfg *config.TtsConfig, name string) (Tts, error) {
+	if r, ok := lookup(name); ok {
+		return r.factory(ctx, cfg)
+	}
+	return nil, fmt.Errorf("unsupported tts engine: %s", name)
+}
+
+func withCache(t Tts, cfg config.CacheConfig) Tts {
+	if !cfg.Enabled {
+		return t
+	}
+
+	cached, err := NewCachedTts(t, cfg.Dir, int64(cfg.MaxMB)<<20)
+	if err != nil {
+		logger.New().Warn(fmt.Sprintf("tts cache disabled: %v", err))
+		return t
+	}
+	return cached
+}
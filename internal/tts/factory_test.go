@@ -0,0 +1,44 @@
+package tts
+
+import (
+	"gofigure/config"
+	"path/filepath"
+	"testing"
+)
+
+func TestActiveEngine(t *testing.T) {
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", filepath.Join(t.TempDir(), "missing.json"))
+
+	piper := config.PiperConfig{Binary: "sh"} // any binary on PATH will do
+	missing := config.PiperConfig{Binary: "no-such-piper"}
+
+	tests := []struct {
+		name    string
+		cfg     config.TtsConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "disabled", cfg: config.TtsConfig{Type: "google"}, want: "dummy"},
+		{name: "configured", cfg: config.TtsConfig{Enabled: true, Type: "piper", Piper: piper}, want: "piper"},
+		{name: "falls back", cfg: config.TtsConfig{Enabled: true, Type: "google", Fallback: []string{"espeak", "piper"}, Piper: piper}, want: "piper"},
+		{name: "nothing available", cfg: config.TtsConfig{Enabled: true, Type: "google", Fallback: []string{"piper"}, Piper: missing}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ActiveEngine(&tt.cfg)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("got (%q, %v), want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveEngineFindsGoogleCredentials(t *testing.T) {
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "factory_test.go") // exists, which is all that is checked
+
+	got, err := ActiveEngine(&config.TtsConfig{Enabled: true, Type: "google"})
+	if err != nil || got != "google" {
+		t.Errorf("got (%q, %v), want google", got, err)
+	}
+}
@@ -1,7 +1,6 @@
 package tts
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"gofigure/internal/game/audio"
@@ -10,9 +9,6 @@ import (
 
 	texttospeech "cloud.google.com/go/texttospeech/apiv1"
 	tts "cloud.google.com/go/texttospeech/apiv1/texttospeechpb"
-
-	"github.com/faiface/beep"
-	"github.com/faiface/beep/wav"
 )
 
 type GoogleTTS struct {
@@ -27,24 +23,27 @@ func NewGoogleTTS(ctx context.Context) (*GoogleTTS, error) {
 	return &GoogleTTS{client: client}, nil
 }
 
-func (g *GoogleTTS) Speak(ctx context.Context, text, emotions, model string) error {
-
-	if model == "" {
-		model = "en-GB-Chirp3-HD-Charon"
+func (g *GoogleTTS) Speak(ctx context.Context, line Line) error {
+	data, err := g.Synthesize(ctx, line)
+	if err != nil {
+		return err
 	}
+	return audio.PlayWAV(ctx, data)
+}
+
+// Synthesize renders the text as 44.1kHz WAV
+func (g *GoogleTTS) Synthesize(ctx context.Context, line Line) ([]byte, error) {
+	model := g.voice(line.Model)
 
 	languageCode := getLanguageCode(model)
 
-	logger.New().Debug(fmt.Sprintf("[tts] [model:%s, prompt:%s]", model, emotions))
+	logger.New().Debug(fmt.Sprintf("[tts] [model:%s, prompt:%s]", model, line.Emotions))
 
 	req := &tts.SynthesizeSpeechRequest{
 		Input: &tts.SynthesisInput{
 			InputSource: &tts.SynthesisInput_Text{
-				Text: text,
+				Text: line.Text,
 			},
-
-			//todo: doesn't seem well support yet..wait a bit?
-			//Prompt: &emotions,
 		},
 		Voice: &tts.VoiceSelectionParams{
 			LanguageCode: languageCode,
@@ -56,31 +55,34 @@ func (g *GoogleTTS) Speak(ctx context.Context, text, emotions, model string) err
 		},
 	}
 
-	resp, err := g.client.SynthesizeSpeech(ctx, req)
-	if err != nil {
-		return err
+	if p, ok := line.prosody(); ok {
+		if supportsSSML(model) {
+			req.Input.InputSource = &tts.SynthesisInput_Ssml{Ssml: p.SSML(line.Text)}
+		} else if p.Rate > 0 {
+			// no SSML for these voices, but they still honour the speaking rate
+			req.AudioConfig.SpeakingRate = p.Rate
+		}
 	}
 
-	done := make(chan bool)
-
-	// Decode audio in memory
-	stream, _, err := wav.Decode(bytes.NewReader(resp.AudioContent))
+	resp, err := g.client.SynthesizeSpeech(ctx, req)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer stream.Close()
 
-	ttsStream := beep.Seq(
-		stream,
-		beep.Callback(func() {
-			done <- true
-		}),
-	)
+	return resp.AudioContent, nil
+}
 
-	audio.PlayTTS(ttsStream)
+// voice is the voice a line is spoken in, the narrator's when none is given
+func (g *GoogleTTS) voice(model string) string {
+	if model == "" {
+		return "en-GB-Chirp3-HD-Charon"
+	}
+	return model
+}
 
-	<-done
-	return nil
+// supportsSSML is false for Chirp voices, which only accept plain text
+func supportsSSML(model string) bool {
+	return !strings.Contains(model, "Chirp")
 }
 
 func getLanguageCode(model string) string {
@@ -0,0 +1,120 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"gofigure/internal/game/audio"
+	"gofigure/internal/logger"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PiperTTS speaks offline by running the piper binary with a local ONNX voice
+type PiperTTS struct {
+	binary       string
+	voicesDir    string
+	defaultVoice string
+}
+
+// NewPiperTTS checks the piper binary exists. Voices are looked up by name in
+// voicesDir, so a mystery can ask for "en_GB-alan-medium".
+func NewPiperTTS(binary, voicesDir, defaultVoice string) (*PiperTTS, error) {
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return nil, fmt.Errorf("piper binary %q not found: %w", binary, err)
+	}
+
+	return &PiperTTS{
+		binary:       path,
+		voicesDir:    voicesDir,
+		defaultVoice: defaultVoice,
+	}, nil
+}
+
+func (p *PiperTTS) Speak(ctx context.Context, line Line) error {
+	data, err := p.Synthesize(ctx, line)
+	if err != nil {
+		return err
+	}
+	return audio.PlayWAV(ctx, data)
+}
+
+// Synthesize renders the text as WAV at the voice's own sample rate
+func (p *PiperTTS) Synthesize(ctx context.Context, line Line) ([]byte, error) {
+	voice, err := p.voicePath(line.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.New().Debug(fmt.Sprintf("[tts] [engine:piper, voice:%s, prompt:%s]", voice, line.Emotions))
+
+	out, err := os.CreateTemp("", "gofigure-piper-*.wav")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create piper output file: %w", err)
+	}
+	out.Close()
+	defer os.Remove(out.Name())
+
+	var stderr bytes.Buffer
+	args := []string{"--model", voice, "--output_file", out.Name()}
+	if prosody, ok := line.prosody(); ok {
+		// piper has no pitch or volume control, only pace
+		if prosody.Rate > 0 {
+			args = append(args, "--length_scale", strconv.FormatFloat(1/prosody.Rate, 'f', 2, 64))
+		}
+		if prosody.PauseMs > 0 {
+			args = append(args, "--sentence_silence", strconv.FormatFloat(float64(prosody.PauseMs)/1000, 'f', 2, 64))
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, p.binary, args...)
+	cmd.Stdin = strings.NewReader(line.Text)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("piper failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read piper output: %w", err)
+	}
+	return data, nil
+}
+
+// voicePath resolves a voice name to its .onnx file. Paths are used as given.
+func (p *PiperTTS) voicePath(model string) (string, error) {
+	model = p.voice(model)
+	if model == "" {
+		return "", fmt.Errorf("no piper voice given and no default voice configured (tts.piper.voice)")
+	}
+
+	path := model
+	if !strings.HasSuffix(path, ".onnx") {
+		path += ".onnx"
+	}
+	if !strings.ContainsRune(model, filepath.Separator) {
+		path = filepath.Join(p.voicesDir, path)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("piper voice %s not found: %w", model, err)
+	}
+	return path, nil
+}
+
+// voice is the voice a line is spoken in, tts.piper.voice when none is given
+func (p *PiperTTS) voice(model string) string {
+	if model == "" {
+		return p.defaultVoice
+	}
+	return model
+}
+
+func (p *PiperTTS) Name() string {
+	return "piper"
+}
@@ -0,0 +1,116 @@
+package tts
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Prosody describes how a line is delivered. Zero values leave the voice unchanged.
+type Prosody struct {
+	Rate    float64 `json:"rate,omitempty"`     // speaking rate, 1 is normal speed
+	Pitch   float64 `json:"pitch,omitempty"`    // semitones above or below the voice's pitch
+	Volume  float64 `json:"volume,omitempty"`   // decibels louder or quieter
+	PauseMs int     `json:"pause_ms,omitempty"` // extra pause at commas and between sentences
+}
+
+// emotionProsody is the default delivery for common emotions. Emotions are
+// free text from the model, so each key also matches words it prefixes,
+// e.g. "nervous" matches "nervously".
+var emotionProsody = map[string]Prosody{
+	"nervous":    {Rate: 1.15, Pitch: 2, PauseMs: 250},
+	"anxious":    {Rate: 1.15, Pitch: 2, PauseMs: 250},
+	"scared":     {Rate: 1.2, Pitch: 3, Volume: -2, PauseMs: 300},
+	"afraid":     {Rate: 1.2, Pitch: 3, Volume: -2, PauseMs: 300},
+	"frightened": {Rate: 1.2, Pitch: 3, Volume: -2, PauseMs: 300},
+	"defensive":  {Rate: 1.05, Pitch: -1, Volume: 2},
+	"angry":      {Rate: 1.1, Pitch: -2, Volume: 4},
+	"furious":    {Rate: 1.15, Pitch: -2, Volume: 6},
+	"annoyed":    {Rate: 1.05, Pitch: -1, Volume: 2},
+	"irritated":  {Rate: 1.05, Pitch: -1, Volume: 2},
+	"sad":        {Rate: 0.85, Pitch: -3, Volume: -3, PauseMs: 400},
+	"grief":      {Rate: 0.8, Pitch: -3, Volume: -4, PauseMs: 500},
+	"upset":      {Rate: 0.9, Pitch: -2, Volume: -2, PauseMs: 300},
+	"calm":       {Rate: 0.95},
+	"composed":   {Rate: 0.95},
+	"happy":      {Rate: 1.1, Pitch: 2, Volume: 1},
+	"cheerful":   {Rate: 1.1, Pitch: 2, Volume: 1},
+	"excited":    {Rate: 1.2, Pitch: 3, Volume: 2},
+	"suspicious": {Rate: 0.9, Pitch: -1, PauseMs: 200},
+	"wary":       {Rate: 0.9, Pitch: -1, PauseMs: 200},
+	"hesitant":   {Rate: 0.9, Volume: -1, PauseMs: 400},
+	"evasive":    {Rate: 0.95, Volume: -1, PauseMs: 300},
+	"smug":       {Rate: 0.95, Pitch: -1, Volume: 1},
+	"arrogant":   {Rate: 0.95, Pitch: -1, Volume: 2},
+}
+
+// ProsodyFor maps a free-text emotion to a delivery, checking a speaker's own
+// overrides before the defaults. The first word that matches wins.
+func ProsodyFor(overrides map[string]Prosody, emotion string) (Prosody, bool) {
+	words := strings.FieldsFunc(strings.ToLower(emotion), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	for _, word := range words {
+		for _, table := range []map[string]Prosody{overrides, emotionProsody} {
+			if p, ok := lookupEmotion(table, word); ok {
+				return p, true
+			}
+		}
+	}
+	return Prosody{}, false
+}
+
+// prosody is how the line should be delivered
+func (l Line) prosody() (Prosody, bool) {
+	return ProsodyFor(l.Prosody, l.Emotions)
+}
+
+func lookupEmotion(table map[string]Prosody, word string) (Prosody, bool) {
+	if p, ok := table[word]; ok {
+		return p, true
+	}
+
+	keys := make([]string, 0, len(table))
+	for k := range table {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if strings.HasPrefix(word, strings.ToLower(k)) {
+			return table[k], true
+		}
+	}
+	return Prosody{}, false
+}
+
+// SSML wraps text in a <speak> document applying the prosody
+func (p Prosody) SSML(text string) string {
+	body := html.EscapeString(text)
+
+	if p.PauseMs > 0 {
+		pause := fmt.Sprintf(`<break time="%dms"/>`, p.PauseMs)
+		for _, punct := range []string{", ", ". ", "? ", "! ", "... "} {
+			body = strings.ReplaceAll(body, punct, strings.TrimSpace(punct)+pause+" ")
+		}
+	}
+
+	var attrs []string
+	if p.Rate > 0 {
+		attrs = append(attrs, fmt.Sprintf(`rate="%.0f%%"`, p.Rate*100))
+	}
+	if p.Pitch != 0 {
+		attrs = append(attrs, fmt.Sprintf(`pitch="%+.1fst"`, p.Pitch))
+	}
+	if p.Volume != 0 {
+		attrs = append(attrs, fmt.Sprintf(`volume="%+.1fdB"`, p.Volume))
+	}
+
+	if len(attrs) > 0 {
+		body = fmt.Sprintf("<prosody %s>%s</prosody>", strings.Join(attrs, " "), body)
+	}
+	return "<speak>" + body + "</speak>"
+}
@@ -0,0 +1,72 @@
+package tts
+
+import "testing"
+
+func TestProsodyFor(t *testing.T) {
+	overrides := map[string]Prosody{
+		"calm":     {Rate: 0.7},
+		"Sheepish": {Pitch: -4},
+	}
+
+	tests := []struct {
+		name      string
+		overrides map[string]Prosody
+		emotion   string
+		want      Prosody
+		wantOk    bool
+	}{
+		{name: "default", emotion: "angry", want: emotionProsody["angry"], wantOk: true},
+		{name: "any case", emotion: "ANGRY", want: emotionProsody["angry"], wantOk: true},
+		{name: "prefix", emotion: "nervously", want: emotionProsody["nervous"], wantOk: true},
+		{name: "first word that matches", emotion: "very sad but angry", want: emotionProsody["sad"], wantOk: true},
+		{name: "punctuation", emotion: "defensive, scared", want: emotionProsody["defensive"], wantOk: true},
+		{name: "unknown", emotion: "peckish", wantOk: false},
+		{name: "empty", emotion: "", wantOk: false},
+		{name: "override", overrides: overrides, emotion: "calm", want: Prosody{Rate: 0.7}, wantOk: true},
+		{name: "override keys ignore case", overrides: overrides, emotion: "sheepishly", want: Prosody{Pitch: -4}, wantOk: true},
+		{name: "defaults behind overrides", overrides: overrides, emotion: "angry", want: emotionProsody["angry"], wantOk: true},
+		{name: "override matched by a later word", overrides: overrides, emotion: "quiet and calm", want: Prosody{Rate: 0.7}, wantOk: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ProsodyFor(tt.overrides, tt.emotion)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("ProsodyFor(%q) = (%+v, %v), want (%+v, %v)", tt.emotion, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestSSML(t *testing.T) {
+	tests := []struct {
+		name    string
+		prosody Prosody
+		text    string
+		want    string
+	}{
+		{name: "plain", text: "I was out.", want: "<speak>I was out.</speak>"},
+		{name: "escaped", text: `Tom & "Vera" <left>`, want: "<speak>Tom &amp; &#34;Vera&#34; &lt;left&gt;</speak>"},
+		{name: "rate", prosody: Prosody{Rate: 1.15}, text: "Hi.", want: `<speak><prosody rate="115%">Hi.</prosody></speak>`},
+		{
+			name:    "pitch and volume",
+			prosody: Prosody{Pitch: -2, Volume: 4},
+			text:    "No.",
+			want:    `<speak><prosody pitch="-2.0st" volume="+4.0dB">No.</prosody></speak>`,
+		},
+		{
+			name:    "pauses",
+			prosody: Prosody{PauseMs: 250},
+			text:    "Well, I... Yes. Why? No! End.",
+			want:    `<speak>Well,<break time="250ms"/> I...<break time="250ms"/> Yes.<break time="250ms"/> Why?<break time="250ms"/> No!<break time="250ms"/> End.</speak>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.prosody.SSML(tt.text); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
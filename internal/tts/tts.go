@@ -3,6 +3,28 @@ package tts
 import "context"
 
 type Tts interface {
-	Speak(ctx context.Context, text, emotions, model string) error
+	Speak(ctx context.Context, line Line) error
 	Name() string
 }
+
+// Line is one thing to say and how to say it
+type Line struct {
+	Text     string
+	Emotions string // free text from the model, mapped onto a delivery
+	Model    string // the engine's voice, "" for its default
+
+	// Prosody is the speaker's own delivery per emotion, checked before the defaults
+	Prosody map[string]Prosody
+}
+
+// Synthesizer is implemented by engines that can render speech to WAV
+// without playing it, which is what lets their output be cached
+type Synthesizer interface {
+	Synthesize(ctx context.Context, line Line) ([]byte, error)
+}
+
+// voicer is implemented by engines that fill in a voice when none is given,
+// so the cache can tell which voice a line is really spoken in
+type voicer interface {
+	voice(model string) string
+}